@@ -0,0 +1,319 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// AddonSpec defines the desired state of Addon: jobs to colocate with the
+// instance groups of the Deployments in the Addon's namespace matching
+// Include, or every Deployment in the namespace if Include is left out,
+// except those matching Exclude
+// +kubebuilder:subresource:status
+type AddonSpec struct {
+	Jobs    []AddonJob      `json:"jobs"`
+	Include *AddonPlacement `json:"include,omitempty"`
+	Exclude *AddonPlacement `json:"exclude,omitempty"`
+}
+
+type AddonJob struct {
+	Source     RoleSource            `json:"source"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// AddonPlacement selects instance groups by the Deployments in the Addon's
+// namespace they belong to, or the jobs they run, given as a job and the name
+// of the Release in the Addon's namespace it comes from. An instance group
+// must match every kind of criterion given, and any one of the values given
+// for each kind
+type AddonPlacement struct {
+	Deployments []string     `json:"deployments,omitempty"`
+	Jobs        []RoleSource `json:"jobs,omitempty"`
+}
+
+// AddonStatus defines the observed state of Addon
+type AddonStatus struct {
+	Available bool     `json:"available"`
+	Failure   *Failure `json:"failure,omitempty"`
+
+	// ReleaseVersions, keyed by Release name, are the versions of the
+	// releases in the runtime config last posted to the Director
+	ReleaseVersions map[string]string `json:"release_versions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Addon is the Schema for the addons API
+type Addon struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AddonSpec   `json:"spec,omitempty"`
+	Status AddonStatus `json:"status,omitempty"`
+}
+
+func (a Addon) BeingDeleted() bool {
+	return !a.GetDeletionTimestamp().IsZero()
+}
+
+var addonFinalizer = strings.Join([]string{"addon", finalizerBase}, ".")
+
+func (a Addon) hasFinalizer() bool {
+	return containsString(a.GetFinalizers(), addonFinalizer)
+}
+
+func (a *Addon) EnsureFinalizer() bool {
+	changed := !a.hasFinalizer()
+	a.SetFinalizers(append(a.GetFinalizers(), addonFinalizer))
+	return changed
+}
+
+func (a *Addon) EnsureNoFinalizer() bool {
+	changed := a.hasFinalizer()
+	a.SetFinalizers(removeString(a.GetFinalizers(), addonFinalizer))
+	return changed
+}
+
+func (a Addon) LastFailure() *Failure {
+	return a.Status.Failure
+}
+
+func (a *Addon) RecordFailure(f *Failure) {
+	a.Status.Failure = f
+}
+
+func (a Addon) PrepareToSave() bool {
+	return false
+}
+
+func (a Addon) InternalName() string {
+	return strings.Join([]string{
+		"addon",
+		a.GetNamespace(),
+		a.GetName(),
+	}, "-")
+}
+
+// UsesRelease is true if any of the Addon's jobs, or the jobs its placement
+// rules select, come from the named Release.
+func (a Addon) UsesRelease(name string) bool {
+	for _, j := range a.Spec.Jobs {
+		if j.Source.Release == name {
+			return true
+		}
+	}
+
+	for _, p := range []*AddonPlacement{a.Spec.Include, a.Spec.Exclude} {
+		if p == nil {
+			continue
+		}
+
+		for _, j := range p.Jobs {
+			if j.Release == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ReferencesSecret is true if any of the Addon's jobs' properties take a
+// value from the named Secret.
+func (a Addon) ReferencesSecret(name string) bool {
+	for _, j := range a.Spec.Jobs {
+		if propertiesReference(j.Properties, func(vs *ValueSource) bool { return vs.ReferencesSecret(name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesConfigMap is true if any of the Addon's jobs' properties take a
+// value from the named ConfigMap.
+func (a Addon) ReferencesConfigMap(name string) bool {
+	for _, j := range a.Spec.Jobs {
+		if propertiesReference(j.Properties, func(vs *ValueSource) bool { return vs.ReferencesConfigMap(name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateUnlessExists posts the Addon to the Director as its own runtime
+// config. A runtime config applies to the whole Director, so it is always
+// limited to the BOSH team of the Team in the Addon's namespace, which owns
+// every Deployment in the namespace, including ones created later.
+func (a *Addon) CreateUnlessExists(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+) error {
+	releases := make(map[string]Release)
+	getRelease := func(name string) (Release, error) {
+		if release, ok := releases[name]; ok {
+			return release, nil
+		}
+
+		var release Release
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{
+				Namespace: a.GetNamespace(),
+				Name:      name,
+			},
+			&release,
+		); err != nil {
+			return Release{}, err
+		}
+
+		releases[name] = release
+		return release, nil
+	}
+
+	addon := remoteclients.Addon{
+		Name: a.InternalName(),
+		Jobs: make([]remoteclients.AddonJob, len(a.Spec.Jobs)),
+	}
+
+	for i, j := range a.Spec.Jobs {
+		release, err := getRelease(j.Source.Release)
+		if err != nil {
+			return err
+		}
+
+		properties, err := resolveProperties(ctx, c, a.GetNamespace(), j.Properties)
+		if err != nil {
+			return err
+		}
+
+		addon.Jobs[i] = remoteclients.AddonJob{
+			Name:       j.Source.Job,
+			Release:    release.Status.OriginalSpec.ReleaseName,
+			Properties: properties,
+		}
+	}
+
+	var err error
+	if addon.Include, err = a.placement(a.Spec.Include, getRelease); err != nil {
+		return err
+	}
+	if addon.Exclude, err = a.placement(a.Spec.Exclude, getRelease); err != nil {
+		return err
+	}
+
+	team, err := namespaceTeam(ctx, c, a.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	if addon.Include == nil {
+		addon.Include = &remoteclients.AddonPlacement{}
+	}
+	addon.Include.Teams = []string{team.BOSHTeam()}
+
+	versions := make(map[string]string)
+	uniqueReleases := make(map[remoteclients.Release]struct{})
+	for _, j := range a.Spec.Jobs {
+		release := releases[j.Source.Release]
+		versions[release.GetName()] = release.BOSHVersion()
+		uniqueReleases[remoteclients.Release{
+			Name:    release.Status.OriginalSpec.ReleaseName,
+			Version: release.BOSHVersion(),
+		}] = struct{}{}
+	}
+
+	runtimeReleases := []remoteclients.Release{}
+	for r, _ := range uniqueReleases {
+		runtimeReleases = append(runtimeReleases, r)
+	}
+
+	if err := bc.CreateAddon(a.InternalName(), runtimeReleases, addon); err != nil {
+		return err
+	}
+
+	a.Status.ReleaseVersions = versions
+	a.Status.Available = true
+
+	return nil
+}
+
+// placement renders a placement rule, naming deployments and releases as
+// they are named in BOSH.
+func (a Addon) placement(
+	p *AddonPlacement,
+	getRelease func(string) (Release, error),
+) (*remoteclients.AddonPlacement, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	placement := remoteclients.AddonPlacement{
+		Deployments: make([]string, len(p.Deployments)),
+		Jobs:        make([]remoteclients.AddonPlacedJob, len(p.Jobs)),
+	}
+
+	for i, name := range p.Deployments {
+		var d Deployment
+		d.SetNamespace(a.GetNamespace())
+		d.SetName(name)
+		placement.Deployments[i] = d.InternalName()
+	}
+
+	for i, j := range p.Jobs {
+		release, err := getRelease(j.Release)
+		if err != nil {
+			return nil, err
+		}
+
+		placement.Jobs[i] = remoteclients.AddonPlacedJob{
+			Name:    j.Job,
+			Release: release.Status.OriginalSpec.ReleaseName,
+		}
+	}
+
+	return &placement, nil
+}
+
+func (a Addon) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	return bc.DeleteAddon(a.InternalName())
+}
+
+// +kubebuilder:object:root=true
+
+// AddonList contains a list of Addon
+type AddonList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Addon `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Addon{}, &AddonList{})
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// ArtifactSource defines where the controller fetches a release or stemcell
+// tarball from itself, for Directors which can't reach its URL; exactly one
+// of its fields must be set
+type ArtifactSource struct {
+	PVC  *PVCArtifactSource  `json:"pvc,omitempty"`
+	HTTP *HTTPArtifactSource `json:"http,omitempty"`
+	OCI  *OCIArtifactSource  `json:"oci,omitempty"`
+	Git  *GitReleaseSource   `json:"git,omitempty"`
+}
+
+// PVCArtifactSource is a path within a PersistentVolumeClaim mounted into the
+// controller in the directory for the resource's namespace, or for another
+// Namespace, whose CompiledRelease at that path is shared with the resource's
+type PVCArtifactSource struct {
+	Namespace string `json:"namespace,omitempty"`
+	ClaimName string `json:"claimName"`
+	Path      string `json:"path"`
+}
+
+// HTTPArtifactSource is a URL reachable from the controller, but not
+// necessarily from the Director
+type HTTPArtifactSource struct {
+	URL string `json:"url"`
+}
+
+// OCIArtifactSource is an image in an OCI registry whose first layer is the
+// tarball, optionally pulled with the credentials in a
+// kubernetes.io/dockerconfigjson Secret in the resource's namespace
+type OCIArtifactSource struct {
+	Image      string `json:"image"`
+	PullSecret string `json:"pullSecret,omitempty"`
+}
+
+// GitReleaseSource is a BOSH release directory in a git repository, at a
+// branch, tag or commit SHA, which the controller creates a release from;
+// only Releases can have a git source. The optional credentials Secret, in
+// the resource's namespace, is either a kubernetes.io/basic-auth or a
+// kubernetes.io/ssh-auth Secret. Repositories cloned over SSH are only
+// trusted if their host key is in KnownHostsFrom, in known_hosts format
+type GitReleaseSource struct {
+	URL               string       `json:"url"`
+	Ref               string       `json:"ref"`
+	CredentialsSecret string       `json:"credentialsSecret,omitempty"`
+	KnownHostsFrom    *ValueSource `json:"knownHostsFrom,omitempty"`
+}
+
+// TracksRef is true if the ref is a branch or tag, whose new commits should
+// become new versions of the release.
+func (s GitReleaseSource) TracksRef() bool {
+	return !remoteclients.IsCommitSHA(s.Ref)
+}
+
+func (s GitReleaseSource) resolve(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+) (remoteclients.GitRelease, error) {
+	repo := remoteclients.GitRelease{URL: s.URL, Ref: s.Ref}
+
+	if s.KnownHostsFrom != nil {
+		var err error
+		if repo.KnownHosts, err = s.KnownHostsFrom.Resolve(ctx, c, namespace); err != nil {
+			return remoteclients.GitRelease{}, err
+		}
+	}
+
+	if s.CredentialsSecret == "" {
+		return repo, nil
+	}
+
+	var secret v1.Secret
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: namespace, Name: s.CredentialsSecret},
+		&secret,
+	); err != nil {
+		return remoteclients.GitRelease{}, err
+	}
+
+	repo.Username = string(secret.Data[v1.BasicAuthUsernameKey])
+	repo.Password = string(secret.Data[v1.BasicAuthPasswordKey])
+	repo.PrivateKey = string(secret.Data[v1.SSHAuthPrivateKey])
+
+	return repo, nil
+}
+
+func (s ArtifactSource) resolve(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+) (remoteclients.ArtifactSource, error) {
+	switch {
+	case s.PVC != nil:
+		pvc := remoteclients.PVCArtifact{
+			Namespace: namespace,
+			ClaimName: s.PVC.ClaimName,
+			Path:      s.PVC.Path,
+		}
+
+		if s.PVC.Namespace != "" && s.PVC.Namespace != namespace {
+			if err := checkSharedArtifact(ctx, c, s.PVC.Namespace, namespace, *s.PVC); err != nil {
+				return remoteclients.ArtifactSource{}, err
+			}
+			pvc.Namespace = s.PVC.Namespace
+		}
+
+		return remoteclients.ArtifactSource{PVC: &pvc}, nil
+	case s.HTTP != nil:
+		return remoteclients.ArtifactSource{URL: s.HTTP.URL}, nil
+	case s.OCI != nil:
+		oci := remoteclients.OCIArtifact{Image: s.OCI.Image}
+
+		if s.OCI.PullSecret != "" {
+			var err error
+			if oci.Username, oci.Password, err = registryCredentials(
+				ctx,
+				c,
+				namespace,
+				s.OCI.PullSecret,
+				s.OCI.Image,
+			); err != nil {
+				return remoteclients.ArtifactSource{}, err
+			}
+		}
+
+		return remoteclients.ArtifactSource{OCI: &oci}, nil
+	case s.Git != nil:
+		return remoteclients.ArtifactSource{}, fmt.Errorf("only releases can have a git source")
+	default:
+		return remoteclients.ArtifactSource{}, fmt.Errorf("source must have one of pvc, http, oci or git")
+	}
+}
+
+// checkSharedArtifact confirms that a CompiledRelease in owner has exported
+// a tarball to the PersistentVolumeClaim path and shared it with namespace,
+// since a tenant can otherwise only read its own claims.
+func checkSharedArtifact(
+	ctx context.Context,
+	c client.Client,
+	owner string,
+	namespace string,
+	pvc PVCArtifactSource,
+) error {
+	var compiledReleases CompiledReleaseList
+	if err := c.List(ctx, &compiledReleases, client.InNamespace(owner)); err != nil {
+		return err
+	}
+
+	for _, cr := range compiledReleases.Items {
+		if cr.sharesWith(namespace, pvc.ClaimName, pvc.Path) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"no CompiledRelease in namespace %s shares %s in PersistentVolumeClaim %s with namespace %s",
+		owner,
+		pvc.Path,
+		pvc.ClaimName,
+		namespace,
+	)
+}
+
+// registryCredentials finds the username and password for an image's
+// registry in a kubernetes.io/dockerconfigjson Secret.
+func registryCredentials(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	secretName string,
+	image string,
+) (string, string, error) {
+	var secret v1.Secret
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: namespace, Name: secretName},
+		&secret,
+	); err != nil {
+		return "", "", err
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+		return "", "", fmt.Errorf("parsing secret %q: %s", secretName, err)
+	}
+
+	registry := strings.SplitN(image, "/", 2)[0]
+	for server, auth := range config.Auths {
+		if server != registry && !strings.HasPrefix(server, "https://"+registry) {
+			continue
+		}
+
+		if auth.Username != "" {
+			return auth.Username, auth.Password, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("parsing secret %q: %s", secretName, err)
+		}
+
+		if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+			return parts[0], parts[1], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no credentials for registry %q in secret %q", registry, secretName)
+}
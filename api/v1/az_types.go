@@ -30,9 +30,11 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
-// AZSpec defines the desired state of AZ
+// AZSpec defines the desired state of AZ. CPI optionally names the CPI, on
+// Directors with several, which the AZ's VMs are created with
 type AZSpec struct {
 	CloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
+	CPI             string                `json:"cpi,omitempty"`
 }
 
 // AZStatus defines the observed state of AZ
@@ -40,7 +42,9 @@ type AZStatus struct {
 	ImmutableFieldsFrozen   bool                  `json:"immutable_fields_frozen"`
 	Warning                 string                `json:"warning"`
 	OriginalCloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
+	OriginalCPI             string                `json:"cpi,omitempty"`
 	Available               bool                  `json:"available"`
+	Failure                 *Failure              `json:"failure,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -76,14 +80,24 @@ func (a *AZ) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (a AZ) LastFailure() *Failure {
+	return a.Status.Failure
+}
+
+func (a *AZ) RecordFailure(f *Failure) {
+	a.Status.Failure = f
+}
+
 func (a *AZ) PrepareToSave() bool {
 	if !a.Status.ImmutableFieldsFrozen {
 		a.Status.ImmutableFieldsFrozen = true
 		a.Status.OriginalCloudProperties = a.Spec.CloudProperties
+		a.Status.OriginalCPI = a.Spec.CPI
 		return true
 	}
 
-	mutated := a.Spec.CloudProperties.String() != a.Status.OriginalCloudProperties.String()
+	mutated := a.Spec.CloudProperties.String() != a.Status.OriginalCloudProperties.String() ||
+		a.Spec.CPI != a.Status.OriginalCPI
 
 	if mutated && a.Status.Warning == "" {
 		a.Status.Warning = resourceMutationWarning
@@ -113,6 +127,7 @@ func (a *AZ) CreateUnlessExists(
 		a.InternalName(),
 		remoteclients.AZ{
 			Name:            a.InternalName(),
+			CPI:             a.Status.OriginalCPI,
 			CloudProperties: a.Status.OriginalCloudProperties,
 		},
 	); err != nil {
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"context"
+	"reflect"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,8 +35,16 @@ import (
 type BaseImageSpec struct {
 	BaseImageName string `json:"baseImageName"`
 	Version       string `json:"version"`
-	URL           string `json:"url"`
+	URL           string `json:"url,omitempty"`
 	SHA1          string `json:"sha1"`
+
+	// Source, if set instead of URL, is fetched by the controller and
+	// uploaded to the Director as a file
+	Source *ArtifactSource `json:"source,omitempty"`
+
+	// Index is where versions are looked up when Version is "latest" or a
+	// constraint such as "~> 315.0"
+	Index *BaseImageIndex `json:"index,omitempty"`
 }
 
 // BaseImageStatus defines the observed state of BaseImage
@@ -43,6 +52,22 @@ type BaseImageStatus struct {
 	Warning      string        `json:"warning"`
 	OriginalSpec BaseImageSpec `json:"originalSpec"`
 	Available    bool          `json:"available"`
+	Failure      *Failure      `json:"failure,omitempty"`
+	Conditions   []Condition   `json:"conditions,omitempty"`
+
+	// ResolvedVersion is the version a base image tracking an index last
+	// resolved to
+	ResolvedVersion string          `json:"resolvedVersion,omitempty"`
+	VersionHistory  []VersionRecord `json:"versionHistory,omitempty"`
+
+	// UploadedVersions is every version which may have been uploaded to the
+	// Director, all of which are deleted along with the resource
+	UploadedVersions []string `json:"uploadedVersions,omitempty"`
+
+	// OperatingSystem and CPI are read back from the Director once the base
+	// image has been uploaded
+	OperatingSystem string `json:"operatingSystem,omitempty"`
+	CPI             string `json:"cpi,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -78,6 +103,36 @@ func (s *BaseImage) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (s BaseImage) LastFailure() *Failure {
+	return s.Status.Failure
+}
+
+func (s *BaseImage) RecordFailure(f *Failure) {
+	s.Status.Failure = f
+}
+
+// SetQueued records, as the Queued condition, whether an upload or deploy is
+// waiting on the Director, given the DirectorBusyError if it is.
+func (s *BaseImage) SetQueued(err error) {
+	s.Status.Conditions = conditionFromError(s.Status.Conditions, QueuedCondition, "DirectorBusy", err)
+}
+
+// BOSHVersion is the version of the stemcell in BOSH: the version in its
+// spec, or the version last resolved from its index.
+func (s BaseImage) BOSHVersion() string {
+	if s.TracksIndex() {
+		return s.Status.ResolvedVersion
+	}
+
+	return s.Status.OriginalSpec.Version
+}
+
+// TracksIndex is true if the base image's version is "latest" or a
+// constraint, to be resolved against its index periodically.
+func (s BaseImage) TracksIndex() bool {
+	return remoteclients.IsVersionConstraint(s.Status.OriginalSpec.Version)
+}
+
 func (s *BaseImage) PrepareToSave() (needsStatusUpdate bool) {
 	originalSpec := s.Status.OriginalSpec
 
@@ -88,7 +143,9 @@ func (s *BaseImage) PrepareToSave() (needsStatusUpdate bool) {
 		mutated := s.Spec.BaseImageName != originalSpec.BaseImageName ||
 			s.Spec.Version != originalSpec.Version ||
 			s.Spec.URL != originalSpec.URL ||
-			s.Spec.SHA1 != originalSpec.SHA1
+			s.Spec.SHA1 != originalSpec.SHA1 ||
+			!reflect.DeepEqual(s.Spec.Source, originalSpec.Source) ||
+			!reflect.DeepEqual(s.Spec.Index, originalSpec.Index)
 
 		if mutated && s.Status.Warning == "" {
 			s.Status.Warning = resourceMutationWarning
@@ -104,43 +161,139 @@ func (s *BaseImage) PrepareToSave() (needsStatusUpdate bool) {
 
 func (s *BaseImage) CreateUnlessExists(
 	bc remoteclients.BOSHClient,
-	_ context.Context,
-	_ client.Client,
+	ctx context.Context,
+	c client.Client,
 ) error {
 	baseImageSpec := s.Status.OriginalSpec
 
-	if present, err := bc.HasBaseImage(
+	if s.TracksIndex() {
+		if err := s.uploadFromIndex(bc); err != nil {
+			return err
+		}
+	} else if present, err := bc.HasBaseImage(
 		baseImageSpec.BaseImageName,
 		baseImageSpec.Version,
 	); err != nil {
 		return err
 	} else if !present {
-		if err := bc.UploadBaseImage(
-			baseImageSpec.URL,
-			baseImageSpec.SHA1,
-		); err != nil {
+		if err := s.upload(bc, ctx, c); err != nil {
 			return err
 		}
 	}
 
+	if err := s.verify(bc); err != nil {
+		return err
+	}
+
 	s.Status.Available = true
 
 	return nil
 }
 
-func (s BaseImage) DeleteIfExists(bc remoteclients.BOSHClient) error {
+// verify reads back the stemcell from the Director, recording its OS and
+// CPI, and fails if the Director doesn't have the stemcell the uploaded
+// tarball was declared to be.
+func (s *BaseImage) verify(bc remoteclients.BOSHClient) error {
+	baseImageSpec := s.Status.OriginalSpec
+	version := s.BOSHVersion()
+
+	metadata, found, err := bc.BaseImageMetadata(baseImageSpec.BaseImageName, version)
+	if err != nil {
+		return err
+	} else if !found {
+		return remoteclients.UploadMismatchError{
+			Kind:    "stemcell",
+			Name:    baseImageSpec.BaseImageName,
+			Version: version,
+		}
+	}
+
+	s.Status.OperatingSystem = metadata.OS
+	s.Status.CPI = metadata.CPI
+
+	return nil
+}
+
+func (s BaseImage) upload(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+) error {
 	baseImageSpec := s.Status.OriginalSpec
 
+	if baseImageSpec.Source == nil {
+		return bc.UploadBaseImage(baseImageSpec.URL, baseImageSpec.SHA1)
+	}
+
+	source, err := baseImageSpec.Source.resolve(ctx, c, s.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	return bc.UploadBaseImageFile(source, baseImageSpec.SHA1)
+}
+
+// uploadFromIndex uploads the newest version of the stemcell in its index
+// which satisfies its version constraint, unless already uploaded.
+func (s *BaseImage) uploadFromIndex(bc remoteclients.BOSHClient) error {
+	baseImageSpec := s.Status.OriginalSpec
+
+	var indexURL string
+	if baseImageSpec.Index != nil {
+		indexURL = baseImageSpec.Index.URL
+	}
+
+	versions, err := remoteclients.BaseImageVersions(indexURL, baseImageSpec.BaseImageName)
+	if err != nil {
+		return err
+	}
+
+	latest, err := latestIndexedVersion(versions, baseImageSpec.Version, baseImageSpec.BaseImageName)
+	if err != nil {
+		return err
+	}
+
 	if present, err := bc.HasBaseImage(
 		baseImageSpec.BaseImageName,
-		baseImageSpec.Version,
+		latest.Version,
 	); err != nil {
 		return err
-	} else if present {
-		return bc.DeleteBaseImage(
+	} else if !present {
+		if err := bc.UploadBaseImage(latest.URL, latest.SHA1); err != nil {
+			return err
+		}
+	}
+
+	s.Status.ResolvedVersion = latest.Version
+	s.Status.VersionHistory = recordVersion(s.Status.VersionHistory, latest.Version)
+	s.Status.UploadedVersions = recordUploadedVersion(s.Status.UploadedVersions, latest.Version)
+
+	return nil
+}
+
+// DeleteIfExists deletes every version of the stemcell it has uploaded, or
+// may have uploaded, to the Director.
+func (s BaseImage) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	baseImageSpec := s.Status.OriginalSpec
+
+	for _, version := range uploadedVersions(
+		s.BOSHVersion(),
+		s.Status.UploadedVersions,
+		s.Status.VersionHistory,
+	) {
+		if present, err := bc.HasBaseImage(
 			baseImageSpec.BaseImageName,
-			baseImageSpec.Version,
-		)
+			version,
+		); err != nil {
+			return err
+		} else if present {
+			if err := bc.DeleteBaseImage(
+				baseImageSpec.BaseImageName,
+				version,
+			); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
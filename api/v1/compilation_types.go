@@ -34,6 +34,7 @@ import (
 type CompilationSpec struct {
 	Replicas              int                   `json:"replicas"`
 	AZCloudProperties     *runtime.RawExtension `json:"az_cloud_properties,omitempty"`
+	AZCPI                 string                `json:"az_cpi,omitempty"`
 	CPU                   int                   `json:"cpu"`
 	RAM                   int                   `json:"ram"`
 	EphemeralDiskSize     int                   `json:"ephemeral_disk_size"`
@@ -49,9 +50,10 @@ type CompilationSpec struct {
 
 // CompilationStatus defines the observed state of Compilation
 type CompilationStatus struct {
-	Warning          string `json:"warning"`
-	OriginalDirector string `json:"original_director"`
-	Available        bool   `json:"available"`
+	Warning          string   `json:"warning"`
+	OriginalDirector string   `json:"original_director"`
+	Available        bool     `json:"available"`
+	Failure          *Failure `json:"failure,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -87,6 +89,14 @@ func (c *Compilation) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (c Compilation) LastFailure() *Failure {
+	return c.Status.Failure
+}
+
+func (c *Compilation) RecordFailure(f *Failure) {
+	c.Status.Failure = f
+}
+
 func (c *Compilation) PrepareToSave() (needsStatusUpdate bool) {
 	originalDirector := c.Status.OriginalDirector
 
@@ -138,6 +148,7 @@ func (c *Compilation) CreateUnlessExists(
 func (c Compilation) boshClientAZ() remoteclients.AZ {
 	return remoteclients.AZ{
 		Name:            c.InternalName(),
+		CPI:             c.Spec.AZCPI,
 		CloudProperties: c.Spec.AZCloudProperties,
 	}
 }
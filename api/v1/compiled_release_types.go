@@ -0,0 +1,259 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// CompiledReleaseSpec defines the desired state of CompiledRelease. The
+// tarball can be imported by Releases in the namespaces it is SharedWith, as
+// well as in its own
+// +kubebuilder:subresource:status
+type CompiledReleaseSpec struct {
+	Release    string                 `json:"release"`
+	Deployment string                 `json:"deployment"`
+	Storage    CompiledReleaseStorage `json:"storage"`
+	SharedWith []string               `json:"shared_with,omitempty"`
+}
+
+// CompiledReleaseStorage is where the compiled release tarball is written: a
+// path within a PersistentVolumeClaim mounted into the controller in the
+// directory for the CompiledRelease's namespace
+type CompiledReleaseStorage struct {
+	ClaimName string `json:"claim_name"`
+	Path      string `json:"path"`
+}
+
+// CompiledReleaseStatus defines the observed state of CompiledRelease
+type CompiledReleaseStatus struct {
+	Warning         string              `json:"warning"`
+	OriginalSpec    CompiledReleaseSpec `json:"original_spec"`
+	Available       bool                `json:"available"`
+	Failure         *Failure            `json:"failure,omitempty"`
+	ReleaseName     string              `json:"release_name,omitempty"`
+	ReleaseVersion  string              `json:"release_version,omitempty"`
+	StemcellOS      string              `json:"stemcell_os,omitempty"`
+	StemcellVersion string              `json:"stemcell_version,omitempty"`
+	SHA1            string              `json:"sha1,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CompiledRelease is the Schema for the compiledreleases API
+type CompiledRelease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CompiledReleaseSpec   `json:"spec,omitempty"`
+	Status CompiledReleaseStatus `json:"status,omitempty"`
+}
+
+func (cr CompiledRelease) BeingDeleted() bool {
+	return !cr.GetDeletionTimestamp().IsZero()
+}
+
+var compiledReleaseFinalizer = strings.Join([]string{"compiledrelease", finalizerBase}, ".")
+
+func (cr CompiledRelease) hasFinalizer() bool {
+	return containsString(cr.GetFinalizers(), compiledReleaseFinalizer)
+}
+
+func (cr *CompiledRelease) EnsureFinalizer() bool {
+	changed := !cr.hasFinalizer()
+	cr.SetFinalizers(append(cr.GetFinalizers(), compiledReleaseFinalizer))
+	return changed
+}
+
+func (cr *CompiledRelease) EnsureNoFinalizer() bool {
+	changed := cr.hasFinalizer()
+	cr.SetFinalizers(removeString(cr.GetFinalizers(), compiledReleaseFinalizer))
+	return changed
+}
+
+func (cr CompiledRelease) LastFailure() *Failure {
+	return cr.Status.Failure
+}
+
+func (cr *CompiledRelease) RecordFailure(f *Failure) {
+	cr.Status.Failure = f
+}
+
+func (cr *CompiledRelease) PrepareToSave() (needsStatusUpdate bool) {
+	originalSpec := cr.Status.OriginalSpec
+
+	if originalSpec.Release == "" {
+		cr.Status.OriginalSpec = cr.Spec
+		needsStatusUpdate = true
+	} else {
+		mutated := !reflect.DeepEqual(cr.Spec, originalSpec)
+
+		if mutated && cr.Status.Warning == "" {
+			cr.Status.Warning = resourceMutationWarning
+			needsStatusUpdate = true
+		} else if !mutated && cr.Status.Warning != "" {
+			cr.Status.Warning = ""
+			needsStatusUpdate = true
+		}
+	}
+
+	return
+}
+
+func (cr CompiledRelease) storage() remoteclients.PVCArtifact {
+	return remoteclients.PVCArtifact{
+		Namespace: cr.GetNamespace(),
+		ClaimName: cr.Status.OriginalSpec.Storage.ClaimName,
+		Path:      cr.Status.OriginalSpec.Storage.Path,
+	}
+}
+
+// CreateUnlessExists exports the release, compiled against the stemcell, as
+// last deployed by the deployment, unless it has already been exported. It
+// won't overwrite a tarball it didn't write itself.
+func (cr *CompiledRelease) CreateUnlessExists(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+) error {
+	if exists, err := remoteclients.PVCArtifactExists(cr.storage(), cr.Status.SHA1); err != nil {
+		return err
+	} else if exists {
+		cr.Status.Available = true
+		return nil
+	}
+
+	spec := cr.Status.OriginalSpec
+
+	var deployment Deployment
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: cr.GetNamespace(), Name: spec.Deployment},
+		&deployment,
+	); err != nil {
+		return err
+	}
+
+	var release Release
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: cr.GetNamespace(), Name: spec.Release},
+		&release,
+	); err != nil {
+		return err
+	}
+
+	releaseVersion, ok := deployment.Status.ReleaseVersions[spec.Release]
+	if !ok || deployment.Status.BaseImageVersion == "" {
+		return fmt.Errorf("deployment %s has not deployed release %s", spec.Deployment, spec.Release)
+	}
+
+	var baseImage BaseImage
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: cr.GetNamespace(), Name: deployment.Spec.BaseImage},
+		&baseImage,
+	); err != nil {
+		return err
+	}
+
+	stemcell, found, err := bc.BaseImageMetadata(
+		baseImage.Status.OriginalSpec.BaseImageName,
+		deployment.Status.BaseImageVersion,
+	)
+	if err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf(
+			"stemcell %s/%s not found",
+			baseImage.Status.OriginalSpec.BaseImageName,
+			deployment.Status.BaseImageVersion,
+		)
+	}
+
+	compiled := remoteclients.CompiledRelease{
+		Release: remoteclients.Release{
+			Name:    release.Status.OriginalSpec.ReleaseName,
+			Version: releaseVersion,
+		},
+		OS:        stemcell.OS,
+		OSVersion: deployment.Status.BaseImageVersion,
+	}
+
+	sha1, err := bc.ExportRelease(deployment.InternalName(), compiled, cr.storage())
+	if err != nil {
+		return err
+	}
+
+	cr.Status.ReleaseName = compiled.Release.Name
+	cr.Status.ReleaseVersion = compiled.Release.Version
+	cr.Status.StemcellOS = compiled.OS
+	cr.Status.StemcellVersion = compiled.OSVersion
+	cr.Status.SHA1 = sha1
+	cr.Status.Available = true
+
+	return nil
+}
+
+// sharesWith is true if the CompiledRelease has exported its tarball to the
+// PersistentVolumeClaim path, and lets Releases in namespace import it.
+func (cr CompiledRelease) sharesWith(namespace, claimName, path string) bool {
+	spec := cr.Status.OriginalSpec
+
+	return cr.Status.SHA1 != "" &&
+		!cr.BeingDeleted() &&
+		containsString(spec.SharedWith, namespace) &&
+		spec.Storage.ClaimName == claimName &&
+		filepath.Clean("/"+spec.Storage.Path) == filepath.Clean("/"+path)
+}
+
+// DeleteIfExists removes the exported tarball, if the tarball at its path is
+// still the one the CompiledRelease wrote; the release compiled in BOSH is
+// left alone, since it belongs to the Release.
+func (cr CompiledRelease) DeleteIfExists(_ remoteclients.BOSHClient) error {
+	if cr.Status.SHA1 == "" {
+		return nil
+	}
+
+	return remoteclients.RemovePVCArtifact(cr.storage(), cr.Status.SHA1)
+}
+
+// +kubebuilder:object:root=true
+
+// CompiledReleaseList contains a list of CompiledRelease
+type CompiledReleaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CompiledRelease `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CompiledRelease{}, &CompiledReleaseList{})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ConditionType string
+
+const (
+	// QueuedCondition is true while an upload or deploy is waiting for the
+	// Director to finish others, because of its concurrency limit
+	QueuedCondition ConditionType = "Queued"
+
+	// LockedCondition is true while a deploy is waiting for another BOSH task
+	// to release its lock on the deployment
+	LockedCondition ConditionType = "Locked"
+
+	// ValidCondition is true once a Role's job and properties have been
+	// checked against the job's spec in its release
+	ValidCondition ConditionType = "Valid"
+)
+
+type Condition struct {
+	Type               ConditionType      `json:"type"`
+	Status             v1.ConditionStatus `json:"status"`
+	Reason             string             `json:"reason,omitempty"`
+	Message            string             `json:"message,omitempty"`
+	LastTransitionTime metav1.Time        `json:"last_transition_time,omitempty"`
+}
+
+// setCondition adds or replaces the condition of the given type, keeping its
+// last transition time unless its status has changed.
+func setCondition(
+	conditions []Condition,
+	conditionType ConditionType,
+	status bool,
+	reason string,
+	message string,
+) []Condition {
+	c := Condition{
+		Type:               conditionType,
+		Status:             v1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	if status {
+		c.Status = v1.ConditionTrue
+	}
+
+	for i, existing := range conditions {
+		if existing.Type == conditionType {
+			if existing.Status == c.Status {
+				c.LastTransitionTime = existing.LastTransitionTime
+			}
+			conditions[i] = c
+			return conditions
+		}
+	}
+
+	return append(conditions, c)
+}
+
+// conditionFromError sets the condition of the given type to true, with the
+// error as its message, if err is not nil, and otherwise sets it to false if
+// it was previously set.
+func conditionFromError(
+	conditions []Condition,
+	conditionType ConditionType,
+	reason string,
+	err error,
+) []Condition {
+	if err != nil {
+		return setCondition(conditions, conditionType, true, reason, err.Error())
+	}
+
+	for _, existing := range conditions {
+		if existing.Type == conditionType {
+			return setCondition(conditions, conditionType, false, "", "")
+		}
+	}
+
+	return conditions
+}
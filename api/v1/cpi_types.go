@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// CPISpec defines the desired state of CPI. Properties can take values, such
+// as IaaS credentials, from Secrets and ConfigMaps in the BOSH system
+// namespace, as {"valueFrom": {"secretKeyRef": {"name": ..., "key": ...}}}
+// +kubebuilder:subresource:status
+type CPISpec struct {
+	Director   string                `json:"director"`
+	Type       string                `json:"type"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// CPIStatus defines the observed state of CPI
+type CPIStatus struct {
+	Warning          string   `json:"warning"`
+	OriginalDirector string   `json:"original_director"`
+	Available        bool     `json:"available"`
+	Failure          *Failure `json:"failure,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CPI is the Schema for the cpis API
+type CPI struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CPISpec   `json:"spec,omitempty"`
+	Status CPIStatus `json:"status,omitempty"`
+}
+
+func (c CPI) BeingDeleted() bool {
+	return !c.GetDeletionTimestamp().IsZero()
+}
+
+var cpiFinalizer = strings.Join([]string{"cpi", finalizerBase}, ".")
+
+func (c CPI) hasFinalizer() bool {
+	return containsString(c.GetFinalizers(), cpiFinalizer)
+}
+
+func (c *CPI) EnsureFinalizer() bool {
+	changed := !c.hasFinalizer()
+	c.SetFinalizers(append(c.GetFinalizers(), cpiFinalizer))
+	return changed
+}
+
+func (c *CPI) EnsureNoFinalizer() bool {
+	changed := c.hasFinalizer()
+	c.SetFinalizers(removeString(c.GetFinalizers(), cpiFinalizer))
+	return changed
+}
+
+func (c CPI) LastFailure() *Failure {
+	return c.Status.Failure
+}
+
+func (c *CPI) RecordFailure(f *Failure) {
+	c.Status.Failure = f
+}
+
+func (c *CPI) PrepareToSave() (needsStatusUpdate bool) {
+	originalDirector := c.Status.OriginalDirector
+
+	if originalDirector == "" {
+		c.Status.OriginalDirector = c.Spec.Director
+		needsStatusUpdate = true
+	} else {
+		mutated := originalDirector != c.Spec.Director
+
+		if mutated && c.Status.Warning == "" {
+			c.Status.Warning = resourceMutationWarning
+			needsStatusUpdate = true
+		} else if !mutated && c.Status.Warning != "" {
+			c.Status.Warning = ""
+			needsStatusUpdate = true
+		}
+	}
+
+	return
+}
+
+func (c CPI) InternalName() string {
+	return strings.Join([]string{
+		"cpi",
+		c.GetNamespace(),
+		c.GetName(),
+	}, "-")
+}
+
+// ReferencesSecret is true if the CPI's properties take a value from the
+// named Secret.
+func (c CPI) ReferencesSecret(name string) bool {
+	return propertiesReference(c.Spec.Properties, func(vs *ValueSource) bool { return vs.ReferencesSecret(name) })
+}
+
+// ReferencesConfigMap is true if the CPI's properties take a value from the
+// named ConfigMap.
+func (c CPI) ReferencesConfigMap(name string) bool {
+	return propertiesReference(c.Spec.Properties, func(vs *ValueSource) bool { return vs.ReferencesConfigMap(name) })
+}
+
+// CreateUnlessExists posts the CPI to the Director as its own cpi-type
+// config. The CPI is named in BOSH by its resource name, which AZs reference
+// it by; CPIs only live in the BOSH system namespace, so the name is unique.
+func (c *CPI) CreateUnlessExists(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	k8sClient client.Client,
+) error {
+	properties, err := resolveProperties(ctx, k8sClient, c.GetNamespace(), c.Spec.Properties)
+	if err != nil {
+		return err
+	}
+
+	if err := bc.CreateCPI(
+		c.InternalName(),
+		remoteclients.CPI{
+			Name:       c.GetName(),
+			Type:       c.Spec.Type,
+			Properties: properties,
+		},
+	); err != nil {
+		return err
+	}
+
+	c.Status.Available = true
+
+	return nil
+}
+
+func (c CPI) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	return bc.DeleteCPI(c.InternalName())
+}
+
+// +kubebuilder:object:root=true
+
+// CPIList contains a list of CPI
+type CPIList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CPI `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CPI{}, &CPIList{})
+}
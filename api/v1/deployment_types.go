@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,19 +38,72 @@ type DeploymentSpec struct {
 	Replicas            int            `json:"replicas"`
 	Containers          []Container    `json:"containers"`
 	Extensions          []string       `json:"extensions,omitempty"`
+	VMType              string         `json:"vm_type,omitempty"`
+	PersistentDiskType  string         `json:"persistent_disk_type,omitempty"`
 	BaseImage           string         `json:"base_image"`
 	Network             string         `json:"network"`
 	UpdateStrategy      UpdateStrategy `json:"update_strategy"`
 	ForceReconciliation bool           `json:"force_reconciliation"`
+
+	// CancelInFlightOnUpdate cancels a deploy task which is still running
+	// when the Deployment is updated, rather than waiting for it to finish
+	// before deploying the update
+	CancelInFlightOnUpdate bool `json:"cancel_in_flight_on_update,omitempty"`
+
+	// RollForward redeploys whenever a referenced Release or BaseImage
+	// resolves to a new version; otherwise the versions last deployed are
+	// kept until the Deployment itself is changed
+	RollForward bool `json:"roll_forward,omitempty"`
+
+	// Variables are credentials generated by the Director's config server,
+	// or by the controller for Directors without one, which Role properties
+	// reference as ((name))
+	Variables []Variable `json:"variables,omitempty"`
+}
+
+// Variable is a password, certificate, SSH key or RSA key generated for the
+// Deployment by the Director's config server, e.g. CredHub
+type Variable struct {
+	Name    string           `json:"name"`
+	Type    string           `json:"type"`
+	Options *VariableOptions `json:"options,omitempty"`
+}
+
+// VariableOptions configure how a variable is generated. CA names another
+// certificate variable of the Deployment, or an absolute config server path,
+// to sign a certificate with; ExtendedKeyUsage may include "server_auth" and
+// "client_auth"; Duration is the number of days a certificate is valid for
+type VariableOptions struct {
+	CA               string   `json:"ca,omitempty"`
+	IsCA             bool     `json:"is_ca,omitempty"`
+	CommonName       string   `json:"common_name,omitempty"`
+	AlternativeNames []string `json:"alternative_names,omitempty"`
+	ExtendedKeyUsage []string `json:"extended_key_usage,omitempty"`
+	Duration         int      `json:"duration,omitempty"`
+	Length           int      `json:"length,omitempty"`
 }
 
 type Container struct {
 	Role                  string                           `json:"role"`
 	ExportedConfiguration map[string]ExportedConfiguration `json:"exported_configuration,omitempty"`
 	ImportedConfiguration map[string]ImportedConfiguration `json:"imported_configuration,omitempty"`
-	Resources             Resources                        `json:"resources"`
+	Resources             *Resources                       `json:"resources,omitempty"`
+
+	// PropertyOverrides are deep merged into the properties of the Role's
+	// jobs for this Deployment only
+	PropertyOverrides []PropertyOverride `json:"property_overrides,omitempty"`
 }
 
+// PropertyOverride is merged into the properties of the job in the
+// container's Role named by Job, which can be left out if the Role has only
+// one job
+type PropertyOverride struct {
+	Job        string                `json:"job,omitempty"`
+	Properties *runtime.RawExtension `json:"properties"`
+}
+
+// Resources are summed across a Deployment's containers to size its VMs and
+// persistent disks, unless the Deployment names a VMType or DiskType instead
 type Resources struct {
 	RAM                int `json:"ram"`
 	CPU                int `json:"cpu"`
@@ -57,14 +111,19 @@ type Resources struct {
 	PersistentDiskSize int `json:"persistent_disk_size,omitempty"`
 }
 
+// ExportedConfiguration and ImportedConfiguration apply to the job in the
+// container's Role named by Job, which can be left out if the Role has only
+// one job, or only one whose spec declares the internal link
 type ExportedConfiguration struct {
 	InternalLink string `json:"internal_link"`
 	Exported     bool   `json:"exported,omitempty"`
+	Job          string `json:"job,omitempty"`
 }
 
 type ImportedConfiguration struct {
 	InternalLink string `json:"internal_link"`
 	ImportedFrom string `json:"imported_from,omitempty"`
+	Job          string `json:"job,omitempty"`
 }
 
 type UpdateStrategy struct {
@@ -77,7 +136,42 @@ type UpdateStrategy struct {
 
 // DeploymentStatus defines the observed state of Deployment
 type DeploymentStatus struct {
-	Available bool `json:"available"`
+	Available             bool        `json:"available"`
+	Failure               *Failure    `json:"failure,omitempty"`
+	Conditions            []Condition `json:"conditions,omitempty"`
+	RunningTaskID         int         `json:"running_task_id,omitempty"`
+	RunningTaskGeneration int64       `json:"running_task_generation,omitempty"`
+
+	// ReleaseVersions, keyed by Release name, and BaseImageVersion are the
+	// versions last deployed, as of the Deployment's VersionsGeneration
+	ReleaseVersions    map[string]string `json:"release_versions,omitempty"`
+	BaseImageVersion   string            `json:"base_image_version,omitempty"`
+	VersionsGeneration int64             `json:"versions_generation,omitempty"`
+
+	// VariablesRenewTime is when the first certificate generated by the
+	// controller for the Deployment is due for renewal
+	VariablesRenewTime *metav1.Time `json:"variables_renew_time,omitempty"`
+
+	// RenderedProperties are the properties of each job last deployed, after
+	// property overrides are merged in but before value sources and
+	// variables are resolved
+	RenderedProperties []RenderedProperties `json:"rendered_properties,omitempty"`
+
+	// PersistentDisks are the CIDs of the persistent disks attached to each
+	// instance, as of the last deploy to finish
+	PersistentDisks []InstanceDisks `json:"persistent_disks,omitempty"`
+}
+
+// InstanceDisks are the persistent disks of an instance, named as group/id
+type InstanceDisks struct {
+	Instance string   `json:"instance"`
+	DiskCIDs []string `json:"disk_cids"`
+}
+
+type RenderedProperties struct {
+	Role       string                `json:"role"`
+	Job        string                `json:"job"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -113,6 +207,27 @@ func (d *Deployment) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (d Deployment) LastFailure() *Failure {
+	return d.Status.Failure
+}
+
+func (d *Deployment) RecordFailure(f *Failure) {
+	d.Status.Failure = f
+}
+
+// SetQueued records, as the Queued condition, whether an upload or deploy is
+// waiting on the Director, given the DirectorBusyError if it is.
+func (d *Deployment) SetQueued(err error) {
+	d.Status.Conditions = conditionFromError(d.Status.Conditions, QueuedCondition, "DirectorBusy", err)
+}
+
+// SetLocked records, as the Locked condition, whether a deploy is waiting for
+// another BOSH task to release its lock, given the DeploymentLockedError if it
+// is.
+func (d *Deployment) SetLocked(err error) {
+	d.Status.Conditions = conditionFromError(d.Status.Conditions, LockedCondition, "DeploymentLocked", err)
+}
+
 func (d Deployment) PrepareToSave() bool {
 	return false
 }
@@ -130,21 +245,143 @@ func (d *Deployment) CreateUnlessExists(
 	ctx context.Context,
 	c client.Client,
 ) error {
-	deployment, err := d.resolveReferences(ctx, c)
+	if d.Status.RunningTaskID != 0 {
+		if err := d.checkRunningTask(bc); err != nil {
+			return err
+		}
+
+		if err := d.recordPersistentDisks(bc); err != nil {
+			return err
+		}
+
+		if d.Status.RunningTaskGeneration == d.GetGeneration() {
+			d.Status.Available = true
+			return nil
+		}
+	}
+
+	generateVariables, err := d.generatesVariables(bc)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := d.resolveReferences(ctx, c, generateVariables)
+	if err != nil {
+		return err
+	}
+
+	if err := d.ensureNotLocked(bc); err != nil {
+		return err
+	}
+
+	task, err := bc.StartDeployment(d.InternalName(), deployment)
+	if err != nil {
+		return err
+	}
+
+	d.Status.RunningTaskID = task.ID
+	d.Status.RunningTaskGeneration = d.GetGeneration()
+
+	return remoteclients.TaskRunningError{TaskID: task.ID}
+}
+
+// checkRunningTask returns a TaskRunningError while the deploy task last
+// started for the Deployment is still running, first cancelling it if it has
+// been superseded by an update and the Deployment's policy is to cancel. Once
+// the task has finished, it is no longer tracked, and an error is returned if
+// it failed or was cancelled. A superseded task's outcome doesn't matter,
+// since the update is deployed next whatever state the task left things in.
+func (d *Deployment) checkRunningTask(bc remoteclients.BOSHClient) error {
+	id := d.Status.RunningTaskID
+	superseded := d.Status.RunningTaskGeneration != d.GetGeneration()
+
+	state, err := bc.TaskState(id)
 	if err != nil {
 		return err
 	}
 
-	if err := bc.CreateDeployment(d.InternalName(), deployment); err != nil {
+	switch state {
+	case "queued", "processing":
+		if superseded && d.Spec.CancelInFlightOnUpdate {
+			if err := bc.CancelTask(id); err != nil {
+				return err
+			}
+		}
+		return remoteclients.TaskRunningError{TaskID: id}
+	case "cancelling":
+		return remoteclients.TaskRunningError{TaskID: id}
+	}
+
+	d.Status.RunningTaskID = 0
+
+	if state == "done" || superseded {
+		return nil
+	}
+
+	return remoteclients.TaskFailedError{ID: id, State: state}
+}
+
+// recordPersistentDisks records the persistent disks of each instance once a
+// deploy task has finished, since deploying may have created, resized or
+// orphaned disks.
+func (d *Deployment) recordPersistentDisks(bc remoteclients.BOSHClient) error {
+	disks, err := bc.DeploymentDisks(d.InternalName())
+	if err != nil {
+		return err
+	}
+
+	d.Status.PersistentDisks = make([]InstanceDisks, len(disks))
+	for i, disk := range disks {
+		d.Status.PersistentDisks[i] = InstanceDisks{
+			Instance: disk.Instance,
+			DiskCIDs: disk.DiskCIDs,
+		}
+	}
+
+	return nil
+}
+
+func (d Deployment) ensureNotLocked(bc remoteclients.BOSHClient) error {
+	locks, err := bc.Locks()
+	if err != nil {
 		return err
 	}
 
-	d.Status.Available = true
+	for _, lock := range locks {
+		if lock.Type == "deployment" &&
+			len(lock.Resource) > 0 &&
+			lock.Resource[0] == d.InternalName() {
+			return remoteclients.DeploymentLockedError{
+				Deployment: d.InternalName(),
+				TaskID:     lock.TaskID,
+			}
+		}
+	}
 
 	return nil
 }
 
-func (d *Deployment) resolveReferences(ctx context.Context, c client.Client) (remoteclients.Deployment, error) {
+// generatesVariables is true if the controller, rather than the Director's
+// config server, should generate the Deployment's variables, because the
+// Director has no config server.
+func (d Deployment) generatesVariables(bc remoteclients.BOSHClient) (bool, error) {
+	if len(d.Spec.Variables) == 0 {
+		return false, nil
+	}
+
+	info, err := bc.Info()
+	if err != nil {
+		return false, err
+	}
+
+	return !info.Features["config_server"], nil
+}
+
+func (d *Deployment) resolveReferences(
+	ctx context.Context,
+	c client.Client,
+	generateVariables bool,
+) (remoteclients.Deployment, error) {
 	deployment := remoteclients.Deployment{
 		Name: d.InternalName(),
 		Update: remoteclients.DeploymentUpdate{
@@ -169,15 +406,119 @@ func (d *Deployment) resolveReferences(ctx context.Context, c client.Client) (re
 		deployment.Stemcells = []remoteclients.Stemcell{stemcell}
 	}
 
-	if instanceGroup, err := d.instanceGroup(ctx, c); err != nil {
+	var variableValues map[string]interface{}
+	if generateVariables {
+		var err error
+		if variableValues, err = d.generatedVariables(ctx, c); err != nil {
+			return remoteclients.Deployment{}, err
+		}
+	} else if variables, err := d.variables(); err != nil {
+		return remoteclients.Deployment{}, err
+	} else {
+		deployment.Variables = variables
+		d.Status.VariablesRenewTime = nil
+	}
+
+	if instanceGroup, err := d.instanceGroup(ctx, c, variableValues); err != nil {
 		return remoteclients.Deployment{}, err
 	} else {
 		deployment.InstanceGroups = []remoteclients.InstanceGroup{instanceGroup}
 	}
 
+	d.Status.VersionsGeneration = d.GetGeneration()
+
 	return deployment, nil
 }
 
+func (d Deployment) variables() ([]remoteclients.Variable, error) {
+	names := make(map[string]string)
+	for _, v := range d.Spec.Variables {
+		names[v.Name] = v.Type
+	}
+
+	variables := make([]remoteclients.Variable, len(d.Spec.Variables))
+	for i, v := range d.Spec.Variables {
+		variables[i] = remoteclients.Variable{Name: v.Name, Type: v.Type}
+
+		if v.Options == nil {
+			continue
+		}
+
+		if ca := v.Options.CA; ca != "" && !strings.HasPrefix(ca, "/") && names[ca] != "certificate" {
+			return nil, fmt.Errorf("variable %s has ca %s, which is not a certificate variable", v.Name, ca)
+		}
+
+		variables[i].Options = &remoteclients.VariableOptions{
+			CA:               v.Options.CA,
+			IsCA:             v.Options.IsCA,
+			CommonName:       v.Options.CommonName,
+			AlternativeNames: v.Options.AlternativeNames,
+			ExtendedKeyUsage: v.Options.ExtendedKeyUsage,
+			Duration:         v.Options.Duration,
+			Length:           v.Options.Length,
+		}
+	}
+
+	return variables, nil
+}
+
+// refreshVersions is true if the latest versions of referenced Releases and
+// BaseImages should be deployed, rather than the versions last deployed.
+func (d Deployment) refreshVersions() bool {
+	return d.Spec.RollForward || d.Status.VersionsGeneration != d.GetGeneration()
+}
+
+// UsesRole is true if any of the Deployment's containers is the named Role.
+func (d Deployment) UsesRole(name string) bool {
+	for _, container := range d.Spec.Containers {
+		if container.Role == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesSecret is true if any of the Deployment's property overrides
+// take a value from the named Secret.
+func (d Deployment) ReferencesSecret(name string) bool {
+	return d.overridesReference(func(vs *ValueSource) bool { return vs.ReferencesSecret(name) })
+}
+
+// ReferencesConfigMap is true if any of the Deployment's property overrides
+// take a value from the named ConfigMap.
+func (d Deployment) ReferencesConfigMap(name string) bool {
+	return d.overridesReference(func(vs *ValueSource) bool { return vs.ReferencesConfigMap(name) })
+}
+
+func (d Deployment) overridesReference(references func(*ValueSource) bool) bool {
+	for _, container := range d.Spec.Containers {
+		for _, o := range container.PropertyOverrides {
+			if propertiesReference(o.Properties, references) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RollsForwardWith is true if the Deployment rolls forward and deploys the
+// named Release or BaseImage.
+func (d Deployment) RollsForwardWith(kind, name string) bool {
+	if !d.Spec.RollForward {
+		return false
+	}
+
+	switch kind {
+	case "Release":
+		_, ok := d.Status.ReleaseVersions[name]
+		return ok
+	case "BaseImage":
+		return d.Spec.BaseImage == name
+	default:
+		return false
+	}
+}
+
 func (d Deployment) maxUnavailable() interface{} {
 	if d.Spec.UpdateStrategy.MaxUnavailablePercent == "" {
 		return d.Spec.UpdateStrategy.MaxUnavailableReplicas
@@ -206,8 +547,9 @@ func (d Deployment) vmStrategy() string {
 	}
 }
 
-func (d Deployment) releases(ctx context.Context, c client.Client) ([]remoteclients.Release, error) {
+func (d *Deployment) releases(ctx context.Context, c client.Client) ([]remoteclients.Release, error) {
 	uniqueReleases := make(map[remoteclients.Release]struct{})
+	versions := make(map[string]string)
 
 	for _, container := range d.Spec.Containers {
 		var role Role
@@ -222,24 +564,34 @@ func (d Deployment) releases(ctx context.Context, c client.Client) ([]remoteclie
 			return nil, err
 		}
 
-		var release Release
-		if err := c.Get(
-			ctx,
-			types.NamespacedName{
-				Namespace: d.GetNamespace(),
-				Name:      role.Spec.Source.Release,
-			},
-			&release,
-		); err != nil {
-			return nil, err
-		}
+		for _, job := range role.RoleJobs() {
+			var release Release
+			if err := c.Get(
+				ctx,
+				types.NamespacedName{
+					Namespace: d.GetNamespace(),
+					Name:      job.Source.Release,
+				},
+				&release,
+			); err != nil {
+				return nil, err
+			}
 
-		uniqueReleases[remoteclients.Release{
-			Name:    release.Status.OriginalSpec.ReleaseName,
-			Version: release.Status.OriginalSpec.Version,
-		}] = struct{}{}
+			version, ok := d.Status.ReleaseVersions[release.GetName()]
+			if !ok || d.refreshVersions() {
+				version = release.BOSHVersion()
+			}
+			versions[release.GetName()] = version
+
+			uniqueReleases[remoteclients.Release{
+				Name:    release.Status.OriginalSpec.ReleaseName,
+				Version: version,
+			}] = struct{}{}
+		}
 	}
 
+	d.Status.ReleaseVersions = versions
+
 	releases := []remoteclients.Release{}
 	for r, _ := range uniqueReleases {
 		releases = append(releases, r)
@@ -249,7 +601,7 @@ func (d Deployment) releases(ctx context.Context, c client.Client) ([]remoteclie
 
 const stemcellAlias = "stemcell"
 
-func (d Deployment) stemcell(ctx context.Context, c client.Client) (remoteclients.Stemcell, error) {
+func (d *Deployment) stemcell(ctx context.Context, c client.Client) (remoteclients.Stemcell, error) {
 	var baseImage BaseImage
 	if err := c.Get(
 		ctx,
@@ -262,27 +614,37 @@ func (d Deployment) stemcell(ctx context.Context, c client.Client) (remoteclient
 		return remoteclients.Stemcell{}, err
 	}
 
+	if d.Status.BaseImageVersion == "" || d.refreshVersions() {
+		d.Status.BaseImageVersion = baseImage.BOSHVersion()
+	}
+
 	return remoteclients.Stemcell{
 		Alias:   stemcellAlias,
 		Name:    baseImage.Status.OriginalSpec.BaseImageName,
-		Version: baseImage.Status.OriginalSpec.Version,
+		Version: d.Status.BaseImageVersion,
 	}, nil
 }
 
-func (d Deployment) instanceGroup(ctx context.Context, c client.Client) (remoteclients.InstanceGroup, error) {
+// instanceGroup renders the Deployment's instance group, merging container
+// property overrides into job properties and interpolating the values of
+// variables generated by the controller into them if there are any. The
+// merged properties are recorded in the Deployment's status.
+func (d *Deployment) instanceGroup(
+	ctx context.Context,
+	c client.Client,
+	variableValues map[string]interface{},
+) (remoteclients.InstanceGroup, error) {
 	instanceGroup := remoteclients.InstanceGroup{
 		Name:         d.InternalName(),
 		AZs:          make([]string, len(d.Spec.AZs)),
 		Instances:    d.Spec.Replicas,
-		Jobs:         make([]remoteclients.Job, len(d.Spec.Containers)),
+		Jobs:         []remoteclients.Job{},
 		VMExtensions: make([]string, len(d.Spec.Extensions)),
-		VMResources: remoteclients.VMResources{
-			RAM:               d.ram(),
-			CPU:               d.cpu(),
-			EphemeralDiskSize: d.ephemeralDiskSize(),
-		},
-		Stemcell:           stemcellAlias,
-		PersistentDiskSize: d.persistentDiskSize(),
+		Stemcell:     stemcellAlias,
+	}
+
+	if err := d.sizeInstanceGroup(ctx, c, &instanceGroup); err != nil {
+		return remoteclients.InstanceGroup{}, err
 	}
 
 	for i, azName := range d.Spec.AZs {
@@ -301,7 +663,8 @@ func (d Deployment) instanceGroup(ctx context.Context, c client.Client) (remotec
 		instanceGroup.AZs[i] = az.InternalName()
 	}
 
-	for i, container := range d.Spec.Containers {
+	var renderedProperties []RenderedProperties
+	for _, container := range d.Spec.Containers {
 		var role Role
 		if err := c.Get(
 			ctx,
@@ -314,27 +677,78 @@ func (d Deployment) instanceGroup(ctx context.Context, c client.Client) (remotec
 			return remoteclients.InstanceGroup{}, err
 		}
 
-		var release Release
-		if err := c.Get(
-			ctx,
-			types.NamespacedName{
-				Namespace: d.GetNamespace(),
-				Name:      role.Spec.Source.Release,
-			},
-			&release,
-		); err != nil {
-			return remoteclients.InstanceGroup{}, err
+		roleJobs := role.RoleJobs()
+		if len(roleJobs) == 0 {
+			return remoteclients.InstanceGroup{}, fmt.Errorf("role %s has no jobs", container.Role)
 		}
 
-		instanceGroup.Jobs[i] = remoteclients.Job{
-			Name:       role.Spec.Source.Job,
-			Release:    release.Status.OriginalSpec.ReleaseName,
-			Consumes:   make(map[string]remoteclients.ConsumesLink),
-			Provides:   make(map[string]remoteclients.ProvidesLink),
-			Properties: role.Spec.Properties,
+		jobs := make([]remoteclients.Job, len(roleJobs))
+		specs := make([]ReleaseJob, len(roleJobs))
+
+		for i, roleJob := range roleJobs {
+			var release Release
+			if err := c.Get(
+				ctx,
+				types.NamespacedName{
+					Namespace: d.GetNamespace(),
+					Name:      roleJob.Source.Release,
+				},
+				&release,
+			); err != nil {
+				return remoteclients.InstanceGroup{}, err
+			}
+
+			rendered, err := overridePropertiesOf(roleJobs, container.PropertyOverrides, i)
+			if err != nil {
+				return remoteclients.InstanceGroup{}, fmt.Errorf("role %s: %s", container.Role, err)
+			}
+
+			renderedProperties = append(renderedProperties, RenderedProperties{
+				Role:       container.Role,
+				Job:        roleJob.Source.Job,
+				Properties: rendered,
+			})
+
+			properties, err := resolveProperties(ctx, c, d.GetNamespace(), rendered)
+			if err != nil {
+				return remoteclients.InstanceGroup{}, fmt.Errorf("role %s: %s", container.Role, err)
+			}
+
+			if variableValues != nil {
+				if properties, err = interpolateProperties(properties, variableValues); err != nil {
+					return remoteclients.InstanceGroup{}, fmt.Errorf("role %s: %s", container.Role, err)
+				}
+			}
+
+			jobs[i] = remoteclients.Job{
+				Name:       roleJob.Source.Job,
+				Release:    release.Status.OriginalSpec.ReleaseName,
+				Consumes:   make(map[string]remoteclients.ConsumesLink),
+				Provides:   make(map[string]remoteclients.ProvidesLink),
+				Properties: properties,
+			}
+
+			for internalLink, from := range roleJob.Consumes {
+				jobs[i].Consumes[internalLink] = remoteclients.ConsumesLink{From: from}
+			}
+
+			for internalLink, as := range roleJob.Provides {
+				jobs[i].Provides[internalLink] = remoteclients.ProvidesLink{As: as}
+			}
+
+			for _, spec := range release.Status.Jobs {
+				if spec.Name == roleJob.Source.Job {
+					specs[i] = spec
+				}
+			}
 		}
 
 		for externalLink, configuration := range container.ImportedConfiguration {
+			i, err := linkedJob(roleJobs, specs, configuration.Job, configuration.InternalLink, true)
+			if err != nil {
+				return remoteclients.InstanceGroup{}, fmt.Errorf("role %s: %s", container.Role, err)
+			}
+
 			var d2 Deployment
 			if err := c.Get(
 				ctx,
@@ -347,18 +761,25 @@ func (d Deployment) instanceGroup(ctx context.Context, c client.Client) (remotec
 				return remoteclients.InstanceGroup{}, err
 			}
 
-			instanceGroup.Jobs[i].Consumes[configuration.InternalLink] = remoteclients.ConsumesLink{
+			jobs[i].Consumes[configuration.InternalLink] = remoteclients.ConsumesLink{
 				From:       externalLink,
 				Deployment: d2.InternalName(),
 			}
 		}
 
 		for externalLink, configuration := range container.ExportedConfiguration {
-			instanceGroup.Jobs[i].Provides[configuration.InternalLink] = remoteclients.ProvidesLink{
+			i, err := linkedJob(roleJobs, specs, configuration.Job, configuration.InternalLink, false)
+			if err != nil {
+				return remoteclients.InstanceGroup{}, fmt.Errorf("role %s: %s", container.Role, err)
+			}
+
+			jobs[i].Provides[configuration.InternalLink] = remoteclients.ProvidesLink{
 				As:     externalLink,
 				Shared: configuration.Exported,
 			}
 		}
+
+		instanceGroup.Jobs = append(instanceGroup.Jobs, jobs...)
 	}
 
 	for i, extensionName := range d.Spec.Extensions {
@@ -393,13 +814,111 @@ func (d Deployment) instanceGroup(ctx context.Context, c client.Client) (remotec
 		}
 	}
 
+	d.Status.RenderedProperties = renderedProperties
+
 	return instanceGroup, nil
 }
 
+// linkedJob finds which of a Role's jobs an imported or exported link
+// belongs to: the job named in its configuration, the Role's only job, or
+// the only job whose spec, as read back from its release, declares the link.
+func linkedJob(roleJobs []RoleJob, specs []ReleaseJob, job, link string, consumed bool) (int, error) {
+	if job != "" {
+		for i, j := range roleJobs {
+			if j.Source.Job == job {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("no job %q for link %q", job, link)
+	}
+
+	if len(roleJobs) == 1 {
+		return 0, nil
+	}
+
+	found := -1
+	for i, spec := range specs {
+		links := spec.Provides
+		if consumed {
+			links = spec.Consumes
+		}
+
+		if containsString(links, link) {
+			if found >= 0 {
+				return 0, fmt.Errorf("link %q is declared by several jobs, set job to choose one", link)
+			}
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return 0, fmt.Errorf("link %q is declared by none of the jobs, set job to choose one", link)
+	}
+
+	return found, nil
+}
+
+// sizeInstanceGroup sets the VM type or resources, and the persistent disk
+// type or size, of the Deployment's instance group. A VMType or DiskType
+// can't be combined with resources summed from containers.
+func (d Deployment) sizeInstanceGroup(
+	ctx context.Context,
+	c client.Client,
+	instanceGroup *remoteclients.InstanceGroup,
+) error {
+	if d.Spec.VMType == "" {
+		instanceGroup.VMResources = &remoteclients.VMResources{
+			RAM:               d.ram(),
+			CPU:               d.cpu(),
+			EphemeralDiskSize: d.ephemeralDiskSize(),
+		}
+	} else if d.ram() != 0 || d.cpu() != 0 || d.ephemeralDiskSize() != 0 {
+		return fmt.Errorf("deployment has vm_type %s, so containers can't set ram, cpu or ephemeral_disk_size", d.Spec.VMType)
+	} else {
+		var vmType VMType
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{
+				Namespace: d.GetNamespace(),
+				Name:      d.Spec.VMType,
+			},
+			&vmType,
+		); err != nil {
+			return err
+		}
+
+		instanceGroup.VMType = vmType.InternalName()
+	}
+
+	if d.Spec.PersistentDiskType == "" {
+		instanceGroup.PersistentDiskSize = d.persistentDiskSize()
+	} else if d.persistentDiskSize() != 0 {
+		return fmt.Errorf("deployment has persistent_disk_type %s, so containers can't set persistent_disk_size", d.Spec.PersistentDiskType)
+	} else {
+		var diskType DiskType
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{
+				Namespace: d.GetNamespace(),
+				Name:      d.Spec.PersistentDiskType,
+			},
+			&diskType,
+		); err != nil {
+			return err
+		}
+
+		instanceGroup.PersistentDiskType = diskType.InternalName()
+	}
+
+	return nil
+}
+
 func (d Deployment) ram() int {
 	ram := 0
 	for _, c := range d.Spec.Containers {
-		ram += c.Resources.RAM
+		if c.Resources != nil {
+			ram += c.Resources.RAM
+		}
 	}
 	return ram
 }
@@ -407,7 +926,9 @@ func (d Deployment) ram() int {
 func (d Deployment) cpu() int {
 	cpu := 0
 	for _, c := range d.Spec.Containers {
-		cpu += c.Resources.CPU
+		if c.Resources != nil {
+			cpu += c.Resources.CPU
+		}
 	}
 	return cpu
 }
@@ -415,7 +936,9 @@ func (d Deployment) cpu() int {
 func (d Deployment) ephemeralDiskSize() int {
 	ephemeralDiskSize := 0
 	for _, c := range d.Spec.Containers {
-		ephemeralDiskSize += c.Resources.EphemeralDiskSize
+		if c.Resources != nil {
+			ephemeralDiskSize += c.Resources.EphemeralDiskSize
+		}
 	}
 	return ephemeralDiskSize
 }
@@ -423,12 +946,33 @@ func (d Deployment) ephemeralDiskSize() int {
 func (d Deployment) persistentDiskSize() int {
 	persistentDiskSize := 0
 	for _, c := range d.Spec.Containers {
-		persistentDiskSize += c.Resources.PersistentDiskSize
+		if c.Resources != nil {
+			persistentDiskSize += c.Resources.PersistentDiskSize
+		}
 	}
 	return persistentDiskSize
 }
 
 func (d Deployment) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	if id := d.Status.RunningTaskID; id != 0 {
+		state, err := bc.TaskState(id)
+		if err != nil {
+			return err
+		}
+
+		// The task holds the deployment's lock until it has finished
+		// cancelling, so the deployment is only deleted after that.
+		switch state {
+		case "queued", "processing":
+			if err := bc.CancelTask(id); err != nil {
+				return err
+			}
+			return remoteclients.TaskRunningError{TaskID: id}
+		case "cancelling":
+			return remoteclients.TaskRunningError{TaskID: id}
+		}
+	}
+
 	return bc.DeleteDeployment(d.InternalName())
 }
 
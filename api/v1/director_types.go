@@ -17,9 +17,15 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"strings"
+	"time"
 
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -27,18 +33,68 @@ import (
 
 // DirectorSpec defines the desired state of Director
 type DirectorSpec struct {
-	URL             string `json:"url"`
-	CACert          string `json:"ca_cert"`
-	UAAURL          string `json:"uaa_url"`
-	UAACACert       string `json:"uaa_ca_cert"`
-	UAAClient       string `json:"uaa_client"`
-	UAAClientSecret string `json:"uaa_client_secret"`
+	URL                 string         `json:"url"`
+	CACert              string         `json:"ca_cert,omitempty"`
+	CACertFrom          *ValueSource   `json:"ca_cert_from,omitempty"`
+	UAAURL              string         `json:"uaa_url"`
+	UAACACert           string         `json:"uaa_ca_cert,omitempty"`
+	UAACACertFrom       *ValueSource   `json:"uaa_ca_cert_from,omitempty"`
+	UAAClient           string         `json:"uaa_client,omitempty"`
+	UAAClientFrom       *ValueSource   `json:"uaa_client_from,omitempty"`
+	UAAClientSecret     string         `json:"uaa_client_secret,omitempty"`
+	UAAClientSecretFrom *ValueSource   `json:"uaa_client_secret_from,omitempty"`
+	Proxy               *DirectorProxy `json:"proxy,omitempty"`
+
+	// MaxConcurrentOperations limits how many release and stemcell uploads
+	// and deploys the controller runs against this Director at once,
+	// overriding the controller's --director-max-concurrent-operations flag
+	MaxConcurrentOperations int `json:"max_concurrent_operations,omitempty"`
+
+	// OrphanedDiskRetention is how long the controller keeps persistent
+	// disks the Director has orphaned, e.g. "168h", before deleting them;
+	// if unset, orphaned disks are kept until deleted by hand
+	OrphanedDiskRetention *metav1.Duration `json:"orphaned_disk_retention,omitempty"`
+}
+
+// DirectorProxy defines how to reach a Director and its UAA through a
+// SOCKS5 proxy, or through an SSH tunnel to a jumpbox, as with BOSH_ALL_PROXY.
+// A jumpbox is only trusted if its host key is in KnownHostsFrom, in
+// known_hosts format
+type DirectorProxy struct {
+	URL            string       `json:"url"`
+	PrivateKeyFrom *ValueSource `json:"private_key_from,omitempty"`
+	KnownHostsFrom *ValueSource `json:"known_hosts_from,omitempty"`
 }
 
 // DirectorStatus defines the observed state of Director
 type DirectorStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	Name                   string          `json:"name,omitempty"`
+	UUID                   string          `json:"uuid,omitempty"`
+	Version                string          `json:"version,omitempty"`
+	CPI                    string          `json:"cpi,omitempty"`
+	UserAuthenticationType string          `json:"user_authentication_type,omitempty"`
+	Features               map[string]bool `json:"features,omitempty"`
+	Reachable              bool            `json:"reachable"`
+	UAAAuthenticated       bool            `json:"uaa_authenticated"`
+	Error                  string          `json:"error,omitempty"`
+	LastCheckedTime        *metav1.Time    `json:"last_checked_time,omitempty"`
+
+	// OrphanedDisks are the persistent disks the Director has orphaned and
+	// still keeps, as of the last health check
+	OrphanedDisks []OrphanedDisk `json:"orphaned_disks,omitempty"`
+}
+
+// OrphanedDisk is a persistent disk the Director has detached from its
+// instance and kept, e.g. because its deployment was deleted or its disk
+// size or type changed. Size is in MB, and Deployment and Instance are named
+// as in BOSH
+type OrphanedDisk struct {
+	CID        string      `json:"cid"`
+	Size       int64       `json:"size"`
+	Deployment string      `json:"deployment"`
+	Instance   string      `json:"instance"`
+	AZ         string      `json:"az,omitempty"`
+	OrphanedAt metav1.Time `json:"orphaned_at"`
 }
 
 // +kubebuilder:object:root=true
@@ -52,6 +108,111 @@ type Director struct {
 	Status DirectorStatus `json:"status,omitempty"`
 }
 
+// DirectorCredentials holds the resolved values of a Director's CA
+// certificates and UAA admin client credentials.
+// +kubebuilder:object:generate=false
+type DirectorCredentials struct {
+	CACert          string
+	UAACACert       string
+	UAAClient       string
+	UAAClientSecret string
+	Proxy           remoteclients.Proxy
+}
+
+const defaultUAAClientSecretKey = "secret"
+
+func (d Director) uaaClientSecretSource() *ValueSource {
+	if d.Spec.UAAClientSecretFrom != nil {
+		return d.Spec.UAAClientSecretFrom
+	}
+
+	return &ValueSource{
+		SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: d.Spec.UAAClientSecret},
+			Key:                  defaultUAAClientSecretKey,
+		},
+	}
+}
+
+func (d Director) Credentials(ctx context.Context, c client.Client) (DirectorCredentials, error) {
+	var creds DirectorCredentials
+	var err error
+
+	if creds.CACert, err = resolveValue(
+		ctx, c, d.GetNamespace(), d.Spec.CACert, d.Spec.CACertFrom,
+	); err != nil {
+		return DirectorCredentials{}, err
+	}
+
+	if creds.UAACACert, err = resolveValue(
+		ctx, c, d.GetNamespace(), d.Spec.UAACACert, d.Spec.UAACACertFrom,
+	); err != nil {
+		return DirectorCredentials{}, err
+	}
+
+	if creds.UAAClient, err = resolveValue(
+		ctx, c, d.GetNamespace(), d.Spec.UAAClient, d.Spec.UAAClientFrom,
+	); err != nil {
+		return DirectorCredentials{}, err
+	}
+
+	if creds.UAAClientSecret, err = resolveValue(
+		ctx, c, d.GetNamespace(), "", d.uaaClientSecretSource(),
+	); err != nil {
+		return DirectorCredentials{}, err
+	}
+
+	if p := d.Spec.Proxy; p != nil {
+		creds.Proxy.URL = p.URL
+		if creds.Proxy.PrivateKey, err = resolveValue(
+			ctx, c, d.GetNamespace(), "", p.PrivateKeyFrom,
+		); err != nil {
+			return DirectorCredentials{}, err
+		}
+		if creds.Proxy.KnownHosts, err = resolveValue(
+			ctx, c, d.GetNamespace(), "", p.KnownHostsFrom,
+		); err != nil {
+			return DirectorCredentials{}, err
+		}
+	}
+
+	return creds, nil
+}
+
+func (d Director) ReferencesSecret(name string) bool {
+	return d.Spec.CACertFrom.ReferencesSecret(name) ||
+		d.Spec.UAACACertFrom.ReferencesSecret(name) ||
+		d.Spec.UAAClientFrom.ReferencesSecret(name) ||
+		d.uaaClientSecretSource().ReferencesSecret(name) ||
+		d.proxyPrivateKeySource().ReferencesSecret(name) ||
+		d.proxyKnownHostsSource().ReferencesSecret(name)
+}
+
+func (d Director) ReferencesConfigMap(name string) bool {
+	return d.Spec.CACertFrom.ReferencesConfigMap(name) ||
+		d.Spec.UAACACertFrom.ReferencesConfigMap(name) ||
+		d.Spec.UAAClientFrom.ReferencesConfigMap(name) ||
+		d.uaaClientSecretSource().ReferencesConfigMap(name) ||
+		d.proxyPrivateKeySource().ReferencesConfigMap(name) ||
+		d.proxyKnownHostsSource().ReferencesConfigMap(name)
+}
+
+func (d Director) proxyPrivateKeySource() *ValueSource {
+	if d.Spec.Proxy == nil {
+		return nil
+	}
+
+	return d.Spec.Proxy.PrivateKeyFrom
+}
+
+func (d Director) proxyKnownHostsSource() *ValueSource {
+	if d.Spec.Proxy == nil {
+		return nil
+	}
+
+	return d.Spec.Proxy.KnownHostsFrom
+}
+
 func (d Director) Team() Team {
 	return Team{
 		ObjectMeta: metav1.ObjectMeta{
@@ -97,6 +258,80 @@ func (d *Director) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (d *Director) CheckHealth(bc remoteclients.BOSHClient, uc remoteclients.UAAClient) error {
+	info, err := bc.Info()
+	if err != nil {
+		return d.HealthCheckFailed(err)
+	}
+
+	d.Status.Reachable = true
+	d.Status.Name = info.Name
+	d.Status.UUID = info.UUID
+	d.Status.Version = info.Version
+	d.Status.CPI = info.CPI
+	d.Status.UserAuthenticationType = info.UserAuthenticationType
+	d.Status.Features = info.Features
+
+	if err := uc.Authenticate(); err != nil {
+		d.Status.UAAAuthenticated = false
+		return d.recordHealthCheck(err)
+	}
+
+	d.Status.UAAAuthenticated = true
+	return d.recordHealthCheck(nil)
+}
+
+// CleanUpOrphanedDisks deletes the disks the Director has kept orphaned for
+// longer than the Director's retention, if it has one, and records the rest.
+func (d *Director) CleanUpOrphanedDisks(bc remoteclients.BOSHClient) error {
+	disks, err := bc.OrphanedDisks()
+	if err != nil {
+		return err
+	}
+
+	var kept []OrphanedDisk
+	for _, disk := range disks {
+		if retention := d.Spec.OrphanedDiskRetention; retention != nil &&
+			time.Since(disk.OrphanedAt) > retention.Duration {
+			if err := bc.DeleteOrphanedDisk(disk.CID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		kept = append(kept, OrphanedDisk{
+			CID:        disk.CID,
+			Size:       int64(disk.Size),
+			Deployment: disk.Deployment,
+			Instance:   disk.Instance,
+			AZ:         disk.AZ,
+			OrphanedAt: metav1.NewTime(disk.OrphanedAt),
+		})
+	}
+
+	d.Status.OrphanedDisks = kept
+	return nil
+}
+
+func (d *Director) HealthCheckFailed(err error) error {
+	d.Status.Reachable = false
+	d.Status.UAAAuthenticated = false
+	return d.recordHealthCheck(err)
+}
+
+func (d *Director) recordHealthCheck(err error) error {
+	now := metav1.Now()
+	d.Status.LastCheckedTime = &now
+
+	if err != nil {
+		d.Status.Error = err.Error()
+	} else {
+		d.Status.Error = ""
+	}
+
+	return err
+}
+
 // +kubebuilder:object:root=true
 
 // DirectorList contains a list of Director
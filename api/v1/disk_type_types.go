@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// DiskTypeSpec defines the desired state of DiskType
+// +kubebuilder:subresource:status
+type DiskTypeSpec struct {
+	DiskSize        int                   `json:"disk_size"`
+	CloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
+}
+
+// DiskTypeStatus defines the observed state of DiskType
+type DiskTypeStatus struct {
+	Warning                 string                `json:"warning"`
+	OriginalDiskSize        int                   `json:"disk_size"`
+	OriginalCloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
+	Available               bool                  `json:"available"`
+	Failure                 *Failure              `json:"failure,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DiskType is the Schema for the disktypes API
+type DiskType struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiskTypeSpec   `json:"spec,omitempty"`
+	Status DiskTypeStatus `json:"status,omitempty"`
+}
+
+func (d DiskType) BeingDeleted() bool {
+	return !d.GetDeletionTimestamp().IsZero()
+}
+
+var diskTypeFinalizer = strings.Join([]string{"disktype", finalizerBase}, ".")
+
+func (d DiskType) hasFinalizer() bool {
+	return containsString(d.GetFinalizers(), diskTypeFinalizer)
+}
+
+func (d *DiskType) EnsureFinalizer() bool {
+	changed := !d.hasFinalizer()
+	d.SetFinalizers(append(d.GetFinalizers(), diskTypeFinalizer))
+	return changed
+}
+
+func (d *DiskType) EnsureNoFinalizer() bool {
+	changed := d.hasFinalizer()
+	d.SetFinalizers(removeString(d.GetFinalizers(), diskTypeFinalizer))
+	return changed
+}
+
+func (d DiskType) LastFailure() *Failure {
+	return d.Status.Failure
+}
+
+func (d *DiskType) RecordFailure(f *Failure) {
+	d.Status.Failure = f
+}
+
+func (d *DiskType) PrepareToSave() (needsStatusUpdate bool) {
+	if d.Status.OriginalDiskSize == 0 {
+		d.Status.OriginalDiskSize = d.Spec.DiskSize
+		d.Status.OriginalCloudProperties = d.Spec.CloudProperties
+		needsStatusUpdate = true
+	} else {
+		mutated := d.Spec.DiskSize != d.Status.OriginalDiskSize ||
+			d.Spec.CloudProperties.String() != d.Status.OriginalCloudProperties.String()
+
+		if mutated && d.Status.Warning == "" {
+			d.Status.Warning = resourceMutationWarning
+			needsStatusUpdate = true
+		} else if !mutated && d.Status.Warning != "" {
+			d.Status.Warning = ""
+			needsStatusUpdate = true
+		}
+	}
+
+	return
+}
+
+func (d DiskType) InternalName() string {
+	return strings.Join([]string{
+		"disktype",
+		d.GetNamespace(),
+		d.GetName(),
+	}, "-")
+}
+
+func (d *DiskType) CreateUnlessExists(
+	bc remoteclients.BOSHClient,
+	_ context.Context,
+	_ client.Client,
+) error {
+	if err := bc.CreateDiskType(
+		d.InternalName(),
+		remoteclients.DiskType{
+			Name:            d.InternalName(),
+			DiskSize:        d.Status.OriginalDiskSize,
+			CloudProperties: d.Status.OriginalCloudProperties,
+		},
+	); err != nil {
+		return err
+	}
+
+	d.Status.Available = true
+
+	return nil
+}
+
+func (d DiskType) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	return bc.DeleteDiskType(d.InternalName())
+}
+
+// +kubebuilder:object:root=true
+
+// DiskTypeList contains a list of DiskType
+type DiskTypeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiskType `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DiskType{}, &DiskTypeList{})
+}
@@ -41,6 +41,7 @@ type ExtensionStatus struct {
 	Warning                 string                `json:"warning"`
 	OriginalCloudProperties *runtime.RawExtension `json:"cloud_properties"`
 	Available               bool                  `json:"available"`
+	Failure                 *Failure              `json:"failure,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -76,6 +77,14 @@ func (e *Extension) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (e Extension) LastFailure() *Failure {
+	return e.Status.Failure
+}
+
+func (e *Extension) RecordFailure(f *Failure) {
+	e.Status.Failure = f
+}
+
 func (e *Extension) PrepareToSave() (needsStatusUpdate bool) {
 	originalCloudProperties := e.Status.OriginalCloudProperties
 
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// Failure records the most recent error reconciling a resource with BOSH or
+// UAA, how it was classified, and how many times in a row reconciling the
+// resource has failed.
+type Failure struct {
+	Class          remoteclients.ErrorClass `json:"class"`
+	Message        string                   `json:"message"`
+	Generation     int64                    `json:"generation"`
+	Attempts       int32                    `json:"attempts"`
+	LastFailedTime metav1.Time              `json:"last_failed_time"`
+}
+
+// NewFailure classifies err and records it as a failure of the given
+// generation of a resource, counting on from the resource's last failure if
+// that was of the same generation.
+func NewFailure(last *Failure, generation int64, err error) *Failure {
+	f := &Failure{
+		Class:          remoteclients.ClassifyError(err),
+		Message:        err.Error(),
+		Generation:     generation,
+		Attempts:       1,
+		LastFailedTime: metav1.Now(),
+	}
+
+	if last != nil && last.Generation == generation {
+		f.Attempts = last.Attempts + 1
+	}
+
+	return f
+}
+
+// Permanent is true if the failure was permanent and the resource's spec has
+// not changed since, so that reconciling it again would fail the same way.
+func (f *Failure) Permanent(generation int64) bool {
+	return f != nil &&
+		f.Class == remoteclients.PermanentErrorClass &&
+		f.Generation == generation
+}
@@ -40,6 +40,20 @@ func removeString(slice []string, s string) (result []string) {
 	return
 }
 
+func equalStrings(s1, s2 []string) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+
+	for i, s := range s1 {
+		if s != s2[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func standardName(namespace, name string) string {
 	return strings.ToLower(
 		base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString(
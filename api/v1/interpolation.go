@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var placeholderPattern = regexp.MustCompile(`\(\(\s*([-\w./]+)\s*\)\)`)
+
+// interpolateProperties replaces ((name)) and ((name.key)) placeholders in
+// job properties with the values of variables, as a config server would.
+func interpolateProperties(
+	properties *runtime.RawExtension,
+	variables map[string]interface{},
+) (*runtime.RawExtension, error) {
+	if properties == nil || len(properties.Raw) == 0 {
+		return properties, nil
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(properties.Raw, &parsed); err != nil {
+		return nil, err
+	}
+
+	interpolated, err := interpolate(parsed, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(interpolated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+// interpolate replaces placeholders in the strings within value. A string
+// which is only a placeholder takes the variable's value, whatever its type,
+// while placeholders within longer strings must refer to strings.
+func interpolate(value interface{}, variables map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			interpolated, err := interpolate(nested, variables)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = interpolated
+		}
+	case []interface{}:
+		for i, nested := range v {
+			interpolated, err := interpolate(nested, variables)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = interpolated
+		}
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			return lookupVariable(m[1], variables)
+		}
+
+		var err error
+		interpolated := placeholderPattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			path := placeholderPattern.FindStringSubmatch(placeholder)[1]
+
+			found, lookupErr := lookupVariable(path, variables)
+			if lookupErr != nil {
+				err = lookupErr
+				return placeholder
+			}
+
+			s, ok := found.(string)
+			if !ok {
+				err = fmt.Errorf("variable %s is not a string, so can't be interpolated within %q", path, v)
+				return placeholder
+			}
+			return s
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return interpolated, nil
+	}
+
+	return value, nil
+}
+
+// lookupVariable finds the value of a variable, or of a field within it if
+// path has further dot-separated keys, e.g. "nats_tls.certificate".
+func lookupVariable(path string, variables map[string]interface{}) (interface{}, error) {
+	keys := strings.Split(path, ".")
+
+	value, ok := variables[keys[0]]
+	if !ok {
+		return nil, fmt.Errorf("undefined variable %s", keys[0])
+	}
+
+	for _, key := range keys[1:] {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("variable %s has no field %s", keys[0], key)
+		}
+
+		if value, ok = m[key]; !ok {
+			return nil, fmt.Errorf("variable %s has no field %s", keys[0], key)
+		}
+	}
+
+	return value, nil
+}
@@ -151,6 +151,7 @@ type NetworkStatus struct {
 	Warning      string      `json:"warning"`
 	OriginalSpec NetworkSpec `json:"original_spec"`
 	Available    bool        `json:"available"`
+	Failure      *Failure    `json:"failure,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -186,6 +187,14 @@ func (n *Network) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (n Network) LastFailure() *Failure {
+	return n.Status.Failure
+}
+
+func (n *Network) RecordFailure(f *Failure) {
+	n.Status.Failure = f
+}
+
 func (n *Network) PrepareToSave() (needsStatusUpdate bool) {
 	originalSpec := n.Status.OriginalSpec
 
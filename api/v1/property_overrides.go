@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// overridePropertiesOf applies a container's property overrides for the job
+// at index i of its Role's jobs, in the order they're given.
+func overridePropertiesOf(
+	roleJobs []RoleJob,
+	overrides []PropertyOverride,
+	i int,
+) (*runtime.RawExtension, error) {
+	properties := roleJobs[i].Properties
+
+	for _, o := range overrides {
+		j, err := overriddenJob(roleJobs, o.Job)
+		if err != nil {
+			return nil, err
+		}
+
+		if j != i {
+			continue
+		}
+
+		if properties, err = mergeProperties(properties, o.Properties); err != nil {
+			return nil, fmt.Errorf("property overrides for job %s: %s", roleJobs[i].Source.Job, err)
+		}
+	}
+
+	return properties, nil
+}
+
+// overriddenJob finds which of a Role's jobs a property override applies
+// to: the job it names, or the Role's only job.
+func overriddenJob(roleJobs []RoleJob, job string) (int, error) {
+	if job == "" {
+		if len(roleJobs) != 1 {
+			return 0, fmt.Errorf("property overrides must name a job, since the role has %d jobs", len(roleJobs))
+		}
+		return 0, nil
+	}
+
+	for i, j := range roleJobs {
+		if j.Source.Job == job {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("property overrides name job %s, which is not in the role", job)
+}
+
+// mergeProperties deep merges overrides into a job's properties, following
+// JSON merge patch semantics (RFC 7386): objects are merged key by key, a
+// null removes a key, and any other value, including an array, replaces the
+// value it overrides. A value source is treated as a single value, so it is
+// replaced rather than merged into.
+func mergeProperties(properties, overrides *runtime.RawExtension) (*runtime.RawExtension, error) {
+	if overrides == nil || len(overrides.Raw) == 0 {
+		return properties, nil
+	}
+
+	var patch interface{}
+	if err := json.Unmarshal(overrides.Raw, &patch); err != nil {
+		return nil, err
+	}
+
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("overrides must be an object")
+	}
+
+	var parsed interface{}
+	if properties != nil && len(properties.Raw) > 0 {
+		if err := json.Unmarshal(properties.Raw, &parsed); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := json.Marshal(mergeValues(parsed, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+func mergeValues(value, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	if _, isValueSource := asValueSource(p); isValueSource {
+		return patch
+	}
+
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		v = make(map[string]interface{}, len(p))
+	} else if _, isValueSource := asValueSource(v); isValueSource {
+		v = make(map[string]interface{}, len(p))
+	}
+
+	for key, nested := range p {
+		if nested == nil {
+			delete(v, key)
+			continue
+		}
+		v[key] = mergeValues(v[key], nested)
+	}
+
+	return v
+}
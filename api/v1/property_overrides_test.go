@@ -0,0 +1,231 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func raw(s string) *runtime.RawExtension {
+	if s == "" {
+		return nil
+	}
+	return &runtime.RawExtension{Raw: []byte(s)}
+}
+
+func assertJSONEqual(t *testing.T, actual *runtime.RawExtension, expected string) {
+	t.Helper()
+
+	var a, e interface{}
+	if actual != nil && len(actual.Raw) > 0 {
+		if err := json.Unmarshal(actual.Raw, &a); err != nil {
+			t.Fatalf("unmarshaling result %s: %s", actual.Raw, err)
+		}
+	}
+	if expected != "" {
+		if err := json.Unmarshal([]byte(expected), &e); err != nil {
+			t.Fatalf("unmarshaling expected %s: %s", expected, err)
+		}
+	}
+
+	if !reflect.DeepEqual(a, e) {
+		t.Errorf("expected %s, got %s", expected, actual.Raw)
+	}
+}
+
+func TestMergeProperties(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		overrides  string
+		expected   string
+		err        string
+	}{
+		{
+			name:       "no overrides",
+			properties: `{"port": 8080}`,
+			overrides:  "",
+			expected:   `{"port": 8080}`,
+		},
+		{
+			name:       "no properties",
+			properties: "",
+			overrides:  `{"port": 8080}`,
+			expected:   `{"port": 8080}`,
+		},
+		{
+			name:       "nested merge",
+			properties: `{"tls": {"enabled": false, "port": 8443}, "port": 8080}`,
+			overrides:  `{"tls": {"enabled": true, "ciphers": "all"}}`,
+			expected:   `{"tls": {"enabled": true, "port": 8443, "ciphers": "all"}, "port": 8080}`,
+		},
+		{
+			name:       "null deletes a key",
+			properties: `{"tls": {"enabled": true, "port": 8443}, "port": 8080}`,
+			overrides:  `{"tls": {"port": null}, "port": null}`,
+			expected:   `{"tls": {"enabled": true}}`,
+		},
+		{
+			name:       "null deletes a missing key",
+			properties: `{"port": 8080}`,
+			overrides:  `{"tls": null}`,
+			expected:   `{"port": 8080}`,
+		},
+		{
+			name:       "arrays replace",
+			properties: `{"servers": ["a", "b", "c"]}`,
+			overrides:  `{"servers": ["d"]}`,
+			expected:   `{"servers": ["d"]}`,
+		},
+		{
+			name:       "object replaces scalar",
+			properties: `{"tls": false}`,
+			overrides:  `{"tls": {"enabled": true}}`,
+			expected:   `{"tls": {"enabled": true}}`,
+		},
+		{
+			name:       "valueFrom replaces a value",
+			properties: `{"db": {"password": "plain", "user": "admin"}}`,
+			overrides:  `{"db": {"password": {"valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}}}`,
+			expected:   `{"db": {"password": {"valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}, "user": "admin"}}`,
+		},
+		{
+			name:       "valueFrom replaces another valueFrom rather than merging",
+			properties: `{"password": {"valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}}`,
+			overrides:  `{"password": {"valueFrom": {"configMapKeyRef": {"name": "db", "key": "password"}}}}`,
+			expected:   `{"password": {"valueFrom": {"configMapKeyRef": {"name": "db", "key": "password"}}}}`,
+		},
+		{
+			name:       "object replaces valueFrom rather than merging",
+			properties: `{"password": {"valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}}`,
+			overrides:  `{"password": {"length": 20}}`,
+			expected:   `{"password": {"length": 20}}`,
+		},
+		{
+			name:       "overrides must be an object",
+			properties: `{"port": 8080}`,
+			overrides:  `["port"]`,
+			err:        "overrides must be an object",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, err := mergeProperties(raw(test.properties), raw(test.overrides))
+
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			assertJSONEqual(t, merged, test.expected)
+		})
+	}
+}
+
+func TestOverridePropertiesOf(t *testing.T) {
+	oneJob := []RoleJob{
+		{Source: RoleSource{Job: "server", Release: "zookeeper"}, Properties: raw(`{"port": 2181}`)},
+	}
+	twoJobs := []RoleJob{
+		{Source: RoleSource{Job: "server", Release: "zookeeper"}, Properties: raw(`{"port": 2181}`)},
+		{Source: RoleSource{Job: "smoke-tests", Release: "zookeeper"}, Properties: raw(`{"timeout": 30}`)},
+	}
+
+	tests := []struct {
+		name      string
+		roleJobs  []RoleJob
+		overrides []PropertyOverride
+		i         int
+		expected  string
+		err       string
+	}{
+		{
+			name:      "unnamed job of a single-job role",
+			roleJobs:  oneJob,
+			overrides: []PropertyOverride{{Properties: raw(`{"port": 2182}`)}},
+			expected:  `{"port": 2182}`,
+		},
+		{
+			name:      "unnamed job of a multi-job role",
+			roleJobs:  twoJobs,
+			overrides: []PropertyOverride{{Properties: raw(`{"port": 2182}`)}},
+			err:       "property overrides must name a job, since the role has 2 jobs",
+		},
+		{
+			name:      "job not in the role",
+			roleJobs:  twoJobs,
+			overrides: []PropertyOverride{{Job: "client", Properties: raw(`{"port": 2182}`)}},
+			err:       "property overrides name job client, which is not in the role",
+		},
+		{
+			name:     "overrides for other jobs are skipped",
+			roleJobs: twoJobs,
+			overrides: []PropertyOverride{
+				{Job: "smoke-tests", Properties: raw(`{"timeout": 60}`)},
+			},
+			i:        0,
+			expected: `{"port": 2181}`,
+		},
+		{
+			name:     "overrides are applied in order",
+			roleJobs: twoJobs,
+			overrides: []PropertyOverride{
+				{Job: "smoke-tests", Properties: raw(`{"timeout": 60, "verbose": true}`)},
+				{Job: "server", Properties: raw(`{"port": 2182}`)},
+				{Job: "smoke-tests", Properties: raw(`{"timeout": 90}`)},
+			},
+			i:        1,
+			expected: `{"timeout": 90, "verbose": true}`,
+		},
+		{
+			name:     "invalid overrides name the job",
+			roleJobs: oneJob,
+			overrides: []PropertyOverride{
+				{Job: "server", Properties: raw(`"port"`)},
+			},
+			err: "property overrides for job server: overrides must be an object",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			properties, err := overridePropertiesOf(test.roleJobs, test.overrides, test.i)
+
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			assertJSONEqual(t, properties, test.expected)
+		})
+	}
+}
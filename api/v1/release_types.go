@@ -18,6 +18,8 @@ package v1
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,8 +36,16 @@ import (
 type ReleaseSpec struct {
 	ReleaseName string `json:"releaseName"`
 	Version     string `json:"version"`
-	URL         string `json:"url"`
-	SHA1        string `json:"sha1"`
+	URL         string `json:"url,omitempty"`
+	SHA1        string `json:"sha1,omitempty"`
+
+	// Source, if set instead of URL, is fetched by the controller and
+	// uploaded to the Director as a file
+	Source *ArtifactSource `json:"source,omitempty"`
+
+	// Index is where versions are looked up when Version is "latest" or a
+	// constraint such as "~> 1.2"
+	Index *ReleaseIndex `json:"index,omitempty"`
 }
 
 // ReleaseStatus defines the observed state of Release
@@ -43,6 +53,33 @@ type ReleaseStatus struct {
 	Warning      string      `json:"warning"`
 	OriginalSpec ReleaseSpec `json:"originalSpec"`
 	Available    bool        `json:"available"`
+	Failure      *Failure    `json:"failure,omitempty"`
+	Conditions   []Condition `json:"conditions,omitempty"`
+
+	// ResolvedVersion is the version a release with a git source was last
+	// created as, or the version a release tracking an index last resolved
+	// to; CommitSHA is the commit a release with a git source was created from
+	ResolvedVersion string          `json:"resolvedVersion,omitempty"`
+	CommitSHA       string          `json:"commitSHA,omitempty"`
+	VersionHistory  []VersionRecord `json:"versionHistory,omitempty"`
+
+	// UploadedVersions is every version which may have been uploaded to the
+	// Director, all of which are deleted along with the resource
+	UploadedVersions []string `json:"uploadedVersions,omitempty"`
+
+	// Jobs and Packages are read back from the Director once the release
+	// has been uploaded
+	Jobs     []ReleaseJob `json:"jobs,omitempty"`
+	Packages []string     `json:"packages,omitempty"`
+}
+
+// ReleaseJob is a job in an uploaded release, with the names of the links
+// it consumes and provides
+type ReleaseJob struct {
+	Name        string   `json:"name"`
+	Fingerprint string   `json:"fingerprint"`
+	Consumes    []string `json:"consumes,omitempty"`
+	Provides    []string `json:"provides,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -78,6 +115,52 @@ func (r *Release) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (r Release) LastFailure() *Failure {
+	return r.Status.Failure
+}
+
+func (r *Release) RecordFailure(f *Failure) {
+	r.Status.Failure = f
+}
+
+// SetQueued records, as the Queued condition, whether an upload or deploy is
+// waiting on the Director, given the DirectorBusyError if it is.
+func (r *Release) SetQueued(err error) {
+	r.Status.Conditions = conditionFromError(r.Status.Conditions, QueuedCondition, "DirectorBusy", err)
+}
+
+// BOSHVersion is the version of the release in BOSH: the version in its
+// spec, or the version last created from its git source or resolved from its
+// index.
+func (r Release) BOSHVersion() string {
+	if r.gitSource() != nil || r.TracksIndex() {
+		return r.Status.ResolvedVersion
+	}
+
+	return r.Status.OriginalSpec.Version
+}
+
+func (r Release) gitSource() *GitReleaseSource {
+	if source := r.Status.OriginalSpec.Source; source != nil {
+		return source.Git
+	}
+
+	return nil
+}
+
+// TracksGitRef is true if the release is created from a git branch or tag,
+// which should be checked periodically for new commits.
+func (r Release) TracksGitRef() bool {
+	git := r.gitSource()
+	return git != nil && git.TracksRef()
+}
+
+// TracksIndex is true if the release's version is "latest" or a constraint,
+// to be resolved against its index periodically.
+func (r Release) TracksIndex() bool {
+	return r.gitSource() == nil && remoteclients.IsVersionConstraint(r.Status.OriginalSpec.Version)
+}
+
 func (r *Release) PrepareToSave() (needsStatusUpdate bool) {
 	originalSpec := r.Status.OriginalSpec
 
@@ -88,7 +171,9 @@ func (r *Release) PrepareToSave() (needsStatusUpdate bool) {
 		mutated := r.Spec.ReleaseName != originalSpec.ReleaseName ||
 			r.Spec.Version != originalSpec.Version ||
 			r.Spec.URL != originalSpec.URL ||
-			r.Spec.SHA1 != originalSpec.SHA1
+			r.Spec.SHA1 != originalSpec.SHA1 ||
+			!reflect.DeepEqual(r.Spec.Source, originalSpec.Source) ||
+			!reflect.DeepEqual(r.Spec.Index, originalSpec.Index)
 
 		if mutated && r.Status.Warning == "" {
 			r.Status.Warning = resourceMutationWarning
@@ -104,43 +189,212 @@ func (r *Release) PrepareToSave() (needsStatusUpdate bool) {
 
 func (r *Release) CreateUnlessExists(
 	bc remoteclients.BOSHClient,
-	_ context.Context,
-	_ client.Client,
+	ctx context.Context,
+	c client.Client,
 ) error {
 	releaseSpec := r.Status.OriginalSpec
 
-	if present, err := bc.HasRelease(
+	if r.gitSource() != nil {
+		if err := r.createFromGit(bc, ctx, c); err != nil {
+			return err
+		}
+	} else if r.TracksIndex() {
+		if err := r.uploadFromIndex(bc); err != nil {
+			return err
+		}
+	} else if present, err := bc.HasRelease(
 		releaseSpec.ReleaseName,
 		releaseSpec.Version,
 	); err != nil {
 		return err
 	} else if !present {
-		if err := bc.UploadRelease(
-			releaseSpec.URL,
-			releaseSpec.SHA1,
-		); err != nil {
+		if err := r.upload(bc, ctx, c); err != nil {
 			return err
 		}
 	}
 
+	if err := r.verify(bc); err != nil {
+		return err
+	}
+
 	r.Status.Available = true
 
 	return nil
 }
 
-func (r Release) DeleteIfExists(bc remoteclients.BOSHClient) error {
+// verify reads back the release from the Director, recording its jobs and
+// packages, and fails if the Director doesn't have the release the uploaded
+// tarball was declared to be.
+func (r *Release) verify(bc remoteclients.BOSHClient) error {
 	releaseSpec := r.Status.OriginalSpec
+	version := r.BOSHVersion()
 
-	if present, err := bc.HasRelease(
+	metadata, found, err := bc.ReleaseMetadata(releaseSpec.ReleaseName, version)
+	if err != nil {
+		return err
+	} else if !found {
+		return remoteclients.UploadMismatchError{
+			Kind:    "release",
+			Name:    releaseSpec.ReleaseName,
+			Version: version,
+		}
+	}
+
+	r.Status.Jobs = make([]ReleaseJob, len(metadata.Jobs))
+	for i, j := range metadata.Jobs {
+		r.Status.Jobs[i] = ReleaseJob{
+			Name:        j.Name,
+			Fingerprint: j.Fingerprint,
+			Consumes:    j.Consumes,
+			Provides:    j.Provides,
+		}
+	}
+	r.Status.Packages = metadata.Packages
+
+	return nil
+}
+
+func (r Release) upload(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+) error {
+	releaseSpec := r.Status.OriginalSpec
+
+	if releaseSpec.Source == nil {
+		return bc.UploadRelease(releaseSpec.URL, releaseSpec.SHA1)
+	}
+
+	source, err := releaseSpec.Source.resolve(ctx, c, r.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	return bc.UploadReleaseFile(source, releaseSpec.SHA1)
+}
+
+// createFromGit creates and uploads a new version of the release whenever
+// its git ref points to a different commit than the one it was last created
+// from, or the version created from that commit is missing from BOSH.
+func (r *Release) createFromGit(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+) error {
+	releaseSpec := r.Status.OriginalSpec
+
+	repo, err := r.gitSource().resolve(ctx, c, r.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	commitSHA, err := remoteclients.ResolveGitRef(repo)
+	if err != nil {
+		return err
+	}
+
+	if commitSHA == r.Status.CommitSHA && r.Status.ResolvedVersion != "" {
+		if present, err := bc.HasRelease(
+			releaseSpec.ReleaseName,
+			r.Status.ResolvedVersion,
+		); err != nil || present {
+			return err
+		}
+	}
+
+	built, err := remoteclients.CreateRelease(
+		repo,
+		commitSHA,
 		releaseSpec.ReleaseName,
 		releaseSpec.Version,
+	)
+	if err != nil {
+		return err
+	}
+	defer built.Cleanup()
+
+	if present, err := bc.HasRelease(
+		releaseSpec.ReleaseName,
+		built.Version,
 	); err != nil {
 		return err
-	} else if present {
-		return bc.DeleteRelease(
+	} else if !present {
+		if err := bc.UploadReleaseFile(
+			remoteclients.ArtifactSource{File: built.Path},
+			"",
+		); err != nil {
+			return err
+		}
+	}
+
+	r.Status.CommitSHA = commitSHA
+	r.Status.ResolvedVersion = built.Version
+	r.Status.VersionHistory = recordVersion(r.Status.VersionHistory, built.Version)
+	r.Status.UploadedVersions = recordUploadedVersion(r.Status.UploadedVersions, built.Version)
+
+	return nil
+}
+
+// uploadFromIndex uploads the newest version of the release in its index
+// which satisfies its version constraint, unless already uploaded.
+func (r *Release) uploadFromIndex(bc remoteclients.BOSHClient) error {
+	releaseSpec := r.Status.OriginalSpec
+
+	if releaseSpec.Index == nil {
+		return fmt.Errorf("release with version %q has no index", releaseSpec.Version)
+	}
+
+	versions, err := remoteclients.ReleaseVersions(releaseSpec.Index.URL, releaseSpec.Index.Repository)
+	if err != nil {
+		return err
+	}
+
+	latest, err := latestIndexedVersion(versions, releaseSpec.Version, releaseSpec.Index.Repository)
+	if err != nil {
+		return err
+	}
+
+	if present, err := bc.HasRelease(
+		releaseSpec.ReleaseName,
+		latest.Version,
+	); err != nil {
+		return err
+	} else if !present {
+		if err := bc.UploadRelease(latest.URL, latest.SHA1); err != nil {
+			return err
+		}
+	}
+
+	r.Status.ResolvedVersion = latest.Version
+	r.Status.VersionHistory = recordVersion(r.Status.VersionHistory, latest.Version)
+	r.Status.UploadedVersions = recordUploadedVersion(r.Status.UploadedVersions, latest.Version)
+
+	return nil
+}
+
+// DeleteIfExists deletes every version of the release it has uploaded, or
+// may have uploaded, to the Director.
+func (r Release) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	releaseSpec := r.Status.OriginalSpec
+
+	for _, version := range uploadedVersions(
+		r.BOSHVersion(),
+		r.Status.UploadedVersions,
+		r.Status.VersionHistory,
+	) {
+		if present, err := bc.HasRelease(
 			releaseSpec.ReleaseName,
-			releaseSpec.Version,
-		)
+			version,
+		); err != nil {
+			return err
+		} else if present {
+			if err := bc.DeleteRelease(
+				releaseSpec.ReleaseName,
+				version,
+			); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -17,17 +17,31 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
-// RoleSpec defines the desired state of Role
+// RoleSpec defines the desired state of Role: either a single job, given by
+// Source and Properties, or several jobs which go together, given by Jobs
+// +kubebuilder:subresource:status
 type RoleSpec struct {
-	Source     RoleSource            `json:"source"`
+	Source     *RoleSource           `json:"source,omitempty"`
 	Properties *runtime.RawExtension `json:"properties,omitempty"`
+	Jobs       []RoleJob             `json:"jobs,omitempty"`
 }
 
 type RoleSource struct {
@@ -35,6 +49,17 @@ type RoleSource struct {
 	Release string `json:"release"`
 }
 
+// RoleJob is one of the jobs in a Role. Consumes maps the names of links the
+// job consumes, as in its spec, to the names other jobs in the Role provide
+// them as, and Provides maps the names of links the job provides to the
+// names it provides them to other jobs as, where these differ
+type RoleJob struct {
+	Source     RoleSource            `json:"source"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+	Consumes   map[string]string     `json:"consumes,omitempty"`
+	Provides   map[string]string     `json:"provides,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // Role is the Schema for the roles API
@@ -42,7 +67,245 @@ type Role struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec RoleSpec `json:"spec,omitempty"`
+	Spec   RoleSpec   `json:"spec,omitempty"`
+	Status RoleStatus `json:"status,omitempty"`
+}
+
+// RoleStatus defines the observed state of Role
+type RoleStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// ReleaseVersions, keyed by Release name, are the versions of the
+	// releases whose job specs the Role was last validated against
+	ReleaseVersions map[string]string `json:"release_versions,omitempty"`
+}
+
+// RoleJobs lists the Role's jobs, whether it has one or several.
+func (r Role) RoleJobs() []RoleJob {
+	if len(r.Spec.Jobs) > 0 {
+		return r.Spec.Jobs
+	}
+
+	if r.Spec.Source != nil {
+		return []RoleJob{{Source: *r.Spec.Source, Properties: r.Spec.Properties}}
+	}
+
+	return nil
+}
+
+// UsesRelease is true if any of the Role's jobs come from the named Release.
+func (r Role) UsesRelease(name string) bool {
+	for _, j := range r.RoleJobs() {
+		if j.Source.Release == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesSecret is true if any of the Role's jobs' properties take a value
+// from the named Secret.
+func (r Role) ReferencesSecret(name string) bool {
+	for _, j := range r.RoleJobs() {
+		if propertiesReference(j.Properties, func(vs *ValueSource) bool { return vs.ReferencesSecret(name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesConfigMap is true if any of the Role's jobs' properties take a
+// value from the named ConfigMap.
+func (r Role) ReferencesConfigMap(name string) bool {
+	for _, j := range r.RoleJobs() {
+		if propertiesReference(j.Properties, func(vs *ValueSource) bool { return vs.ReferencesConfigMap(name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Role) setValid(valid bool, reason, message string) {
+	r.Status.Conditions = setCondition(r.Status.Conditions, ValidCondition, valid, reason, message)
+}
+
+// Validate checks each of the Role's jobs and their properties against the
+// job's spec in the current version of its Release, and records the result
+// as the Valid condition. An error is returned only if the check couldn't be
+// made.
+func (r *Role) Validate(bc remoteclients.BOSHClient, ctx context.Context, c client.Client) error {
+	jobs := r.RoleJobs()
+	if len(jobs) == 0 {
+		r.setValid(false, "NoJobs", "role must have either a source or jobs")
+		return nil
+	}
+
+	r.Status.ReleaseVersions = make(map[string]string)
+
+	var reason string
+	var problems []string
+	var warnings []string
+
+	for _, job := range jobs {
+		jobReason, message, missing, err := r.validateJob(bc, ctx, c, job)
+		if err != nil {
+			return err
+		}
+
+		if jobReason != "" {
+			if reason == "" {
+				reason = jobReason
+			}
+			problems = append(problems, fmt.Sprintf("job %s: %s", job.Source.Job, message))
+		}
+
+		if len(missing) > 0 {
+			warnings = append(warnings, fmt.Sprintf("job %s: %s", job.Source.Job, strings.Join(missing, ", ")))
+		}
+	}
+
+	if reason != "" {
+		r.setValid(false, reason, strings.Join(problems, "; "))
+		return nil
+	}
+
+	// Properties declared without a default are often optional in the job's
+	// templates, or given by a Deployment's property overrides or by BOSH
+	// variables, so they're only reported.
+	if len(warnings) > 0 {
+		r.setValid(true, "MissingProperties", "properties without defaults not given: "+strings.Join(warnings, "; "))
+		return nil
+	}
+
+	r.setValid(true, "Valid", "")
+
+	return nil
+}
+
+// validateJob returns why a job isn't valid, if it isn't, and otherwise any
+// properties declared without a default which the job isn't given.
+func (r *Role) validateJob(
+	bc remoteclients.BOSHClient,
+	ctx context.Context,
+	c client.Client,
+	job RoleJob,
+) (reason string, message string, missing []string, err error) {
+	var release Release
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: r.GetNamespace(), Name: job.Source.Release},
+		&release,
+	); apierrs.IsNotFound(err) {
+		return "ReleaseNotFound", fmt.Sprintf("release %q not found", job.Source.Release), nil, nil
+	} else if err != nil {
+		return "", "", nil, err
+	}
+
+	if !release.Status.Available {
+		return "ReleaseNotAvailable", fmt.Sprintf("release %q is not yet available", job.Source.Release), nil, nil
+	}
+
+	releaseName := release.Status.OriginalSpec.ReleaseName
+	version := release.BOSHVersion()
+
+	spec, found, err := bc.ReleaseJobSpec(releaseName, version, job.Source.Job)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	r.Status.ReleaseVersions[job.Source.Release] = version
+
+	if !found {
+		jobs := make([]string, len(release.Status.Jobs))
+		for i, j := range release.Status.Jobs {
+			jobs[i] = j.Name
+		}
+
+		return "JobNotFound", fmt.Sprintf(
+			"not found in release %s/%s, which has jobs: %s",
+			releaseName,
+			version,
+			strings.Join(jobs, ", "),
+		), nil, nil
+	}
+
+	properties := map[string]interface{}{}
+	if job.Properties != nil && len(job.Properties.Raw) > 0 {
+		if err := json.Unmarshal(job.Properties.Raw, &properties); err != nil {
+			return "InvalidProperties", fmt.Sprintf("properties must be a map: %s", err), nil, nil
+		}
+	}
+
+	if unknown := unknownProperties(properties, "", spec); len(unknown) > 0 {
+		return "InvalidProperties", "unknown properties: " + strings.Join(unknown, ", "), nil, nil
+	}
+
+	return "", "", missingProperties(properties, spec), nil
+}
+
+// unknownProperties lists the dotted paths of properties which aren't in the
+// job spec, descending into nested maps until a declared property is found.
+func unknownProperties(properties map[string]interface{}, prefix string, spec remoteclients.JobSpec) []string {
+	var unknown []string
+
+	for key, value := range properties {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if _, ok := spec.Properties[path]; ok {
+			continue
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && declaresPropertiesUnder(spec, path) {
+			unknown = append(unknown, unknownProperties(nested, path, spec)...)
+			continue
+		}
+
+		unknown = append(unknown, path)
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+func declaresPropertiesUnder(spec remoteclients.JobSpec, path string) bool {
+	for name := range spec.Properties {
+		if strings.HasPrefix(name, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// missingProperties lists the properties in the job spec which have no
+// default and aren't given.
+func missingProperties(properties map[string]interface{}, spec remoteclients.JobSpec) []string {
+	var missing []string
+
+	for name, property := range spec.Properties {
+		if !property.HasDefault && !hasProperty(properties, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+	return missing
+}
+
+func hasProperty(properties map[string]interface{}, name string) bool {
+	var value interface{} = properties
+	for _, key := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
 // +kubebuilder:object:root=true
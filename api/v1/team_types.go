@@ -17,9 +17,13 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/amitkgupta/boshv3/remote-clients"
 )
@@ -29,15 +33,18 @@ import (
 
 // TeamSpec defines the desired state of Team
 type TeamSpec struct {
-	Director string `json:"director"`
+	Director    string   `json:"director"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 // TeamStatus defines the observed state of Team
 type TeamStatus struct {
-	Warning          string `json:"warning"`
-	OriginalDirector string `json:"original_director"`
-	SecretNamespace  string `json:"secret_namespace"`
-	Available        bool   `json:"available"`
+	Warning          string   `json:"warning"`
+	OriginalDirector string   `json:"original_director"`
+	SecretNamespace  string   `json:"secret_namespace"`
+	Authorities      []string `json:"authorities,omitempty"`
+	Available        bool     `json:"available"`
+	Failure          *Failure `json:"failure,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -73,6 +80,14 @@ func (t *Team) EnsureNoFinalizer() bool {
 	return changed
 }
 
+func (t Team) LastFailure() *Failure {
+	return t.Status.Failure
+}
+
+func (t *Team) RecordFailure(f *Failure) {
+	t.Status.Failure = f
+}
+
 func (t *Team) PrepareToSave(secretNamespace string) (needsStatusUpdate bool) {
 	originalDirector := t.Status.OriginalDirector
 
@@ -105,19 +120,73 @@ func (t Team) SecretNamespace() string {
 	return t.Status.SecretNamespace
 }
 
+// BOSHTeam is the BOSH team which an admin Team's UAA client belongs to, and
+// so which owns the deployments it creates.
+func (t Team) BOSHTeam() string {
+	return t.ClientName()
+}
+
+const defaultTeamPermission = "admin"
+
+var teamPermissionAuthorities = map[string][]string{
+	"admin":  []string{"bosh.admin"},
+	"read":   []string{"bosh.read"},
+	"upload": []string{"bosh.stemcells.upload", "bosh.releases.upload"},
+}
+
+func (t Team) authorities() ([]string, error) {
+	permissions := t.Spec.Permissions
+	if len(permissions) == 0 {
+		permissions = []string{defaultTeamPermission}
+	}
+
+	var authorities []string
+	for _, p := range permissions {
+		if as, ok := teamPermissionAuthorities[p]; !ok {
+			return nil, fmt.Errorf("unknown team permission %q", p)
+		} else {
+			for _, a := range as {
+				if !containsString(authorities, a) {
+					authorities = append(authorities, a)
+				}
+			}
+		}
+
+		if teamAdmin := "bosh.teams." + t.BOSHTeam() + ".admin"; p == "admin" && !containsString(authorities, teamAdmin) {
+			authorities = append(authorities, teamAdmin)
+		}
+	}
+
+	sort.Strings(authorities)
+	return authorities, nil
+}
+
 func (t *Team) CreateUnlessExists(uc remoteclients.UAAClient, secretData string) error {
+	authorities, err := t.authorities()
+	if err != nil {
+		return err
+	}
+
 	if present, err := uc.HasClient(t.ClientName()); err != nil {
 		return err
 	} else if !present {
 		if err := uc.CreateClient(
 			t.ClientName(),
 			secretData,
-			[]string{"bosh.admin"},
+			authorities,
+		); err != nil {
+			return err
+		}
+	} else if !equalStrings(t.Status.Authorities, authorities) {
+		if err := uc.UpdateClient(
+			t.ClientName(),
+			authorities,
 		); err != nil {
 			return err
 		}
 	}
 
+	t.Status.Authorities = authorities
 	t.Status.Available = true
 
 	return nil
@@ -133,6 +202,21 @@ func (t Team) DeleteIfExists(uc remoteclients.UAAClient) error {
 	return nil
 }
 
+// namespaceTeam finds the one Team in a namespace, whose client acts on
+// behalf of every other resource in the namespace.
+func namespaceTeam(ctx context.Context, c client.Client, namespace string) (Team, error) {
+	var teams TeamList
+	if err := c.List(ctx, &teams, client.InNamespace(namespace)); err != nil {
+		return Team{}, err
+	}
+
+	if len(teams.Items) != 1 {
+		return Team{}, fmt.Errorf("found %d teams in namespace %s", len(teams.Items), namespace)
+	}
+
+	return teams.Items[0], nil
+}
+
 // +kubebuilder:object:root=true
 
 // TeamList contains a list of Team
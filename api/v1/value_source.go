@@ -0,0 +1,216 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValueSource selects a value from a key in a Secret or ConfigMap in the same
+// namespace as the resource referencing it. Exactly one of its fields should
+// be set.
+type ValueSource struct {
+	SecretKeyRef    *v1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+func (vs ValueSource) Resolve(ctx context.Context, c client.Client, namespace string) (string, error) {
+	if ref := vs.SecretKeyRef; ref != nil {
+		var secret v1.Secret
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{Namespace: namespace, Name: ref.Name},
+			&secret,
+		); err != nil {
+			return "", err
+		}
+
+		if value, ok := secret.Data[ref.Key]; ok {
+			return string(value), nil
+		} else if ref.Optional != nil && *ref.Optional {
+			return "", nil
+		} else {
+			return "", fmt.Errorf("key %q not found in secret %q", ref.Key, ref.Name)
+		}
+	}
+
+	if ref := vs.ConfigMapKeyRef; ref != nil {
+		var configMap v1.ConfigMap
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{Namespace: namespace, Name: ref.Name},
+			&configMap,
+		); err != nil {
+			return "", err
+		}
+
+		if value, ok := configMap.Data[ref.Key]; ok {
+			return value, nil
+		} else if value, ok := configMap.BinaryData[ref.Key]; ok {
+			return string(value), nil
+		} else if ref.Optional != nil && *ref.Optional {
+			return "", nil
+		} else {
+			return "", fmt.Errorf("key %q not found in config map %q", ref.Key, ref.Name)
+		}
+	}
+
+	return "", errors.New("value source must reference either a secret or a config map")
+}
+
+func (vs *ValueSource) ReferencesSecret(name string) bool {
+	return vs != nil && vs.SecretKeyRef != nil && vs.SecretKeyRef.Name == name
+}
+
+func (vs *ValueSource) ReferencesConfigMap(name string) bool {
+	return vs != nil && vs.ConfigMapKeyRef != nil && vs.ConfigMapKeyRef.Name == name
+}
+
+func resolveValue(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	value string,
+	source *ValueSource,
+) (string, error) {
+	if source == nil {
+		return value, nil
+	}
+
+	return source.Resolve(ctx, c, namespace)
+}
+
+// valueFromKey marks a value in job properties which is resolved from a
+// Secret or ConfigMap rather than given in plaintext, as in
+// {"valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}}.
+const valueFromKey = "valueFrom"
+
+// asValueSource returns the ValueSource a property value references, if it
+// is of the form {"valueFrom": ...}.
+func asValueSource(value interface{}) (ValueSource, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return ValueSource{}, false
+	}
+
+	from, ok := m[valueFromKey]
+	if !ok {
+		return ValueSource{}, false
+	}
+
+	encoded, err := json.Marshal(from)
+	if err != nil {
+		return ValueSource{}, false
+	}
+
+	var vs ValueSource
+	if err := json.Unmarshal(encoded, &vs); err != nil || (vs.SecretKeyRef == nil && vs.ConfigMapKeyRef == nil) {
+		return ValueSource{}, false
+	}
+
+	return vs, true
+}
+
+// mapValueSources walks a property value, replacing each value source in it
+// with the result of f.
+func mapValueSources(value interface{}, f func(ValueSource) (interface{}, error)) (interface{}, error) {
+	if vs, ok := asValueSource(value); ok {
+		return f(vs)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			mapped, err := mapValueSources(nested, f)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = mapped
+		}
+	case []interface{}:
+		for i, nested := range v {
+			mapped, err := mapValueSources(nested, f)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = mapped
+		}
+	}
+
+	return value, nil
+}
+
+// resolveProperties replaces each value source in job properties with the
+// value it references, when rendering a manifest.
+func resolveProperties(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	properties *runtime.RawExtension,
+) (*runtime.RawExtension, error) {
+	if properties == nil || len(properties.Raw) == 0 {
+		return properties, nil
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(properties.Raw, &parsed); err != nil {
+		return nil, err
+	}
+
+	resolved, err := mapValueSources(parsed, func(vs ValueSource) (interface{}, error) {
+		return vs.Resolve(ctx, c, namespace)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+// propertiesReference is true if any value source in job properties
+// satisfies references.
+func propertiesReference(properties *runtime.RawExtension, references func(*ValueSource) bool) bool {
+	if properties == nil || len(properties.Raw) == 0 {
+		return false
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(properties.Raw, &parsed); err != nil {
+		return false
+	}
+
+	found := false
+	mapValueSources(parsed, func(vs ValueSource) (interface{}, error) {
+		found = found || references(&vs)
+		return nil, nil
+	})
+
+	return found
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// VariableDeploymentLabel and VariableNameLabel mark the Secrets holding
+	// variables generated by the controller for Deployments
+	VariableDeploymentLabel = "bosh.akgupta.ca/deployment"
+	VariableNameLabel       = "bosh.akgupta.ca/variable"
+
+	variableOptionsAnnotation = "bosh.akgupta.ca/variable-options"
+
+	defaultPasswordLength      = 20
+	defaultCertificateDuration = 365
+	maxCertificateRenewBefore  = 30 * 24 * time.Hour
+	rsaKeyBits                 = 2048
+)
+
+// variableGenerator generates the variables of a Deployment, and stores them
+// in Secrets in its namespace, for Directors without a config server.
+type variableGenerator struct {
+	ctx context.Context
+	c   client.Client
+	d   *Deployment
+
+	declared  map[string]Variable
+	generated map[string]map[string][]byte
+	visiting  map[string]bool
+	renewAt   *time.Time
+}
+
+// generatedVariables ensures each of the Deployment's variables has been
+// generated and stored in a Secret, regenerating those whose options have
+// changed, certificates due for renewal, and certificates whose CA has been
+// regenerated, and returns their values for interpolation.
+func (d *Deployment) generatedVariables(ctx context.Context, c client.Client) (map[string]interface{}, error) {
+	g := variableGenerator{
+		ctx:       ctx,
+		c:         c,
+		d:         d,
+		declared:  make(map[string]Variable),
+		generated: make(map[string]map[string][]byte),
+		visiting:  make(map[string]bool),
+	}
+
+	for _, v := range d.Spec.Variables {
+		g.declared[v.Name] = v
+	}
+
+	values := make(map[string]interface{})
+
+	for _, v := range d.Spec.Variables {
+		data, err := g.ensure(v.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Type == "password" {
+			values[v.Name] = string(data["value"])
+		} else {
+			fields := make(map[string]interface{})
+			for key, value := range data {
+				fields[key] = string(value)
+			}
+			values[v.Name] = fields
+		}
+	}
+
+	if g.renewAt != nil {
+		renewAt := metav1.NewTime(*g.renewAt)
+		d.Status.VariablesRenewTime = &renewAt
+	} else {
+		d.Status.VariablesRenewTime = nil
+	}
+
+	return values, nil
+}
+
+func (d Deployment) variableSecretName(variable string) string {
+	return strings.Join([]string{
+		d.GetName(),
+		"var",
+		strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(variable)),
+	}, "-")
+}
+
+func (g *variableGenerator) ensure(name string) (map[string][]byte, error) {
+	if data, ok := g.generated[name]; ok {
+		return data, nil
+	}
+
+	v, ok := g.declared[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined variable %s", name)
+	}
+
+	if g.visiting[name] {
+		return nil, fmt.Errorf("variable %s is signed by itself", name)
+	}
+	g.visiting[name] = true
+	defer delete(g.visiting, name)
+
+	var ca map[string][]byte
+	if v.Type == "certificate" && v.Options != nil && v.Options.CA != "" {
+		if g.declared[v.Options.CA].Type != "certificate" {
+			return nil, fmt.Errorf("variable %s has ca %s, which is not a certificate variable", name, v.Options.CA)
+		}
+
+		var err error
+		if ca, err = g.ensure(v.Options.CA); err != nil {
+			return nil, err
+		}
+	}
+
+	options, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret v1.Secret
+	err = g.c.Get(
+		g.ctx,
+		types.NamespacedName{Namespace: g.d.GetNamespace(), Name: g.d.variableSecretName(name)},
+		&secret,
+	)
+
+	switch {
+	case err == nil:
+		if secret.Labels[VariableNameLabel] != name {
+			return nil, fmt.Errorf("secret %s belongs to variable %q, not %q", secret.GetName(), secret.Labels[VariableNameLabel], name)
+		}
+
+		if secret.Annotations[variableOptionsAnnotation] == string(options) && !dueForRenewal(v, secret.Data, ca) {
+			g.generated[name] = secret.Data
+			g.noteRenewal(v, secret.Data)
+			return secret.Data, nil
+		}
+
+		if secret.Data, err = generateVariable(v, ca); err != nil {
+			return nil, err
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[variableOptionsAnnotation] = string(options)
+
+		if err := g.c.Update(g.ctx, &secret); err != nil {
+			return nil, err
+		}
+	case apierrs.IsNotFound(err):
+		isController := true
+		secret = v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      g.d.variableSecretName(name),
+				Namespace: g.d.GetNamespace(),
+				Labels: map[string]string{
+					VariableDeploymentLabel: g.d.GetName(),
+					VariableNameLabel:       name,
+				},
+				Annotations: map[string]string{variableOptionsAnnotation: string(options)},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: GroupVersion.String(),
+					Kind:       "Deployment",
+					Name:       g.d.GetName(),
+					UID:        g.d.GetUID(),
+					Controller: &isController,
+				}},
+			},
+			Type: v1.SecretTypeOpaque,
+		}
+
+		if secret.Data, err = generateVariable(v, ca); err != nil {
+			return nil, err
+		}
+
+		if err := g.c.Create(g.ctx, &secret); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	g.generated[name] = secret.Data
+	g.noteRenewal(v, secret.Data)
+
+	return secret.Data, nil
+}
+
+// noteRenewal keeps track of when the first certificate is due for renewal,
+// so that the Deployment can be reconciled again then.
+func (g *variableGenerator) noteRenewal(v Variable, data map[string][]byte) {
+	if v.Type != "certificate" {
+		return
+	}
+
+	cert, err := parseCertificate(data["certificate"])
+	if err != nil {
+		return
+	}
+
+	renewAt := renewalTime(cert)
+	if g.renewAt == nil || renewAt.Before(*g.renewAt) {
+		g.renewAt = &renewAt
+	}
+}
+
+// renewalTime is a third of the way before a certificate expires, or 30 days
+// before if sooner.
+func renewalTime(cert *x509.Certificate) time.Time {
+	renewBefore := cert.NotAfter.Sub(cert.NotBefore) / 3
+	if renewBefore > maxCertificateRenewBefore {
+		renewBefore = maxCertificateRenewBefore
+	}
+	return cert.NotAfter.Add(-renewBefore)
+}
+
+func dueForRenewal(v Variable, data map[string][]byte, ca map[string][]byte) bool {
+	if v.Type != "certificate" {
+		return false
+	}
+
+	cert, err := parseCertificate(data["certificate"])
+	if err != nil || !time.Now().Before(renewalTime(cert)) {
+		return true
+	}
+
+	return ca != nil && !bytes.Equal(data["ca"], ca["certificate"])
+}
+
+func generateVariable(v Variable, ca map[string][]byte) (map[string][]byte, error) {
+	options := VariableOptions{}
+	if v.Options != nil {
+		options = *v.Options
+	}
+
+	switch v.Type {
+	case "password":
+		return generatePassword(options)
+	case "certificate":
+		return generateCertificate(options, ca)
+	case "ssh":
+		return generateSSHKey()
+	case "rsa":
+		return generateRSAKey()
+	default:
+		return nil, fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+	}
+}
+
+func generatePassword(options VariableOptions) (map[string][]byte, error) {
+	length := options.Length
+	if length == 0 {
+		length = defaultPasswordLength
+	}
+
+	password := make([]byte, length)
+	if _, err := rand.Read(password); err != nil {
+		return nil, err
+	}
+
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	for i, b := range password {
+		password[i] = alphabet[b%byte(len(alphabet))]
+	}
+
+	return map[string][]byte{"value": password}, nil
+}
+
+func generateCertificate(options VariableOptions, ca map[string][]byte) (map[string][]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	duration := options.Duration
+	if duration == 0 {
+		duration = defaultCertificateDuration
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: options.CommonName},
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, duration),
+		BasicConstraintsValid: true,
+		IsCA:                  options.IsCA,
+	}
+
+	if options.IsCA {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	}
+
+	for _, name := range options.AlternativeNames {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	for _, usage := range options.ExtendedKeyUsage {
+		switch usage {
+		case "server_auth":
+			template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+		case "client_auth":
+			template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+		default:
+			return nil, fmt.Errorf("unknown extended key usage %q", usage)
+		}
+	}
+
+	parent, signer := template, key
+	if ca != nil {
+		if parent, err = parseCertificate(ca["certificate"]); err != nil {
+			return nil, err
+		}
+		if signer, err = parsePrivateKey(ca["private_key"]); err != nil {
+			return nil, err
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	caCertificate := certificate
+	if ca != nil {
+		caCertificate = ca["certificate"]
+	}
+
+	return map[string][]byte{
+		"ca":          caCertificate,
+		"certificate": certificate,
+		"private_key": encodePrivateKey(key),
+	}, nil
+}
+
+func generateSSHKey() (map[string][]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"private_key":            encodePrivateKey(key),
+		"public_key":             bytes.TrimSpace(ssh.MarshalAuthorizedKey(public)),
+		"public_key_fingerprint": []byte(ssh.FingerprintLegacyMD5(public)),
+	}, nil
+}
+
+func generateRSAKey() (map[string][]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"private_key": encodePrivateKey(key),
+		"public_key":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}),
+	}, nil
+}
+
+func encodePrivateKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func parseCertificate(encoded []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(encoded)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM-encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKey(encoded []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(encoded)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM-encoded private key found")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func mustGenerateCertificate(t *testing.T, options VariableOptions, ca map[string][]byte) map[string][]byte {
+	t.Helper()
+
+	data, err := generateCertificate(options, ca)
+	if err != nil {
+		t.Fatalf("generating certificate: %s", err)
+	}
+	return data
+}
+
+func mustParseCertificate(t *testing.T, encoded []byte) *x509.Certificate {
+	t.Helper()
+
+	cert, err := parseCertificate(encoded)
+	if err != nil {
+		t.Fatalf("parsing certificate: %s", err)
+	}
+	return cert
+}
+
+// issuedAt reissues a certificate signed by ca as if it had been issued at
+// notBefore, for a lifetime of the given number of days.
+func issuedAt(t *testing.T, data, ca map[string][]byte, notBefore time.Time, days int) map[string][]byte {
+	t.Helper()
+
+	template := mustParseCertificate(t, data["certificate"])
+	template.NotBefore = notBefore
+	template.NotAfter = notBefore.AddDate(0, 0, days)
+
+	key, err := parsePrivateKey(data["private_key"])
+	if err != nil {
+		t.Fatalf("parsing private key: %s", err)
+	}
+	signer, err := parsePrivateKey(ca["private_key"])
+	if err != nil {
+		t.Fatalf("parsing CA private key: %s", err)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, mustParseCertificate(t, ca["certificate"]), &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("reissuing certificate: %s", err)
+	}
+
+	return map[string][]byte{
+		"ca":          ca["certificate"],
+		"certificate": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		"private_key": data["private_key"],
+	}
+}
+
+func TestGenerateCertificate(t *testing.T) {
+	ca := mustGenerateCertificate(t, VariableOptions{IsCA: true, CommonName: "ca"}, nil)
+	caCert := mustParseCertificate(t, ca["certificate"])
+
+	t.Run("self-signed CA", func(t *testing.T) {
+		if !caCert.IsCA || caCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+			t.Errorf("expected a CA certificate which can sign certificates")
+		}
+		if err := caCert.CheckSignatureFrom(caCert); err != nil {
+			t.Errorf("expected a self-signed certificate: %s", err)
+		}
+		if !bytes.Equal(ca["ca"], ca["certificate"]) {
+			t.Errorf("expected a self-signed certificate to be its own ca")
+		}
+	})
+
+	t.Run("signed by a CA", func(t *testing.T) {
+		data := mustGenerateCertificate(t, VariableOptions{
+			CA:               "ca",
+			CommonName:       "zookeeper",
+			AlternativeNames: []string{"zookeeper.internal", "10.0.0.1"},
+			ExtendedKeyUsage: []string{"server_auth", "client_auth"},
+			Duration:         30,
+		}, ca)
+		cert := mustParseCertificate(t, data["certificate"])
+
+		if err := cert.CheckSignatureFrom(caCert); err != nil {
+			t.Errorf("expected certificate to be signed by the CA: %s", err)
+		}
+		if !bytes.Equal(data["ca"], ca["certificate"]) {
+			t.Errorf("expected ca to be the CA's certificate")
+		}
+		if cert.IsCA {
+			t.Errorf("expected a certificate which isn't a CA")
+		}
+		if cert.Subject.CommonName != "zookeeper" {
+			t.Errorf("expected common name zookeeper, got %s", cert.Subject.CommonName)
+		}
+		if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "zookeeper.internal" {
+			t.Errorf("expected DNS names [zookeeper.internal], got %v", cert.DNSNames)
+		}
+		if len(cert.IPAddresses) != 1 || cert.IPAddresses[0].String() != "10.0.0.1" {
+			t.Errorf("expected IP addresses [10.0.0.1], got %v", cert.IPAddresses)
+		}
+		if len(cert.ExtKeyUsage) != 2 {
+			t.Errorf("expected server and client auth extended key usages, got %v", cert.ExtKeyUsage)
+		}
+		if lifetime := cert.NotAfter.Sub(cert.NotBefore); lifetime != 30*24*time.Hour {
+			t.Errorf("expected a lifetime of 30 days, got %s", lifetime)
+		}
+
+		if _, err := parsePrivateKey(data["private_key"]); err != nil {
+			t.Errorf("expected an RSA private key: %s", err)
+		}
+	})
+
+	t.Run("unknown extended key usage", func(t *testing.T) {
+		_, err := generateCertificate(VariableOptions{ExtendedKeyUsage: []string{"code_signing"}}, nil)
+		if err == nil || err.Error() != `unknown extended key usage "code_signing"` {
+			t.Errorf("expected unknown extended key usage error, got %v", err)
+		}
+	})
+}
+
+func TestDueForRenewal(t *testing.T) {
+	certificate := Variable{Name: "tls", Type: "certificate", Options: &VariableOptions{CA: "ca"}}
+
+	ca := mustGenerateCertificate(t, VariableOptions{IsCA: true, CommonName: "ca"}, nil)
+	otherCA := mustGenerateCertificate(t, VariableOptions{IsCA: true, CommonName: "ca"}, nil)
+
+	current := mustGenerateCertificate(t, *certificate.Options, ca)
+	shortLived := mustGenerateCertificate(t, VariableOptions{CA: "ca", Duration: 1}, ca)
+	aging := issuedAt(t, current, ca, time.Now().AddDate(0, 0, -25), 30)
+
+	tests := []struct {
+		name     string
+		v        Variable
+		data     map[string][]byte
+		ca       map[string][]byte
+		expected bool
+	}{
+		{
+			name:     "not a certificate",
+			v:        Variable{Name: "password", Type: "password"},
+			data:     map[string][]byte{"value": []byte("hunter2")},
+			expected: false,
+		},
+		{
+			name:     "current certificate",
+			v:        certificate,
+			data:     current,
+			ca:       ca,
+			expected: false,
+		},
+		{
+			name:     "current self-signed certificate",
+			v:        Variable{Name: "ca", Type: "certificate", Options: &VariableOptions{IsCA: true}},
+			data:     ca,
+			expected: false,
+		},
+		{
+			name:     "short-lived certificate not yet due",
+			v:        certificate,
+			data:     shortLived,
+			ca:       ca,
+			expected: false,
+		},
+		{
+			name:     "less than a third of its lifetime left",
+			v:        certificate,
+			data:     aging,
+			ca:       ca,
+			expected: true,
+		},
+		{
+			name:     "CA has been regenerated",
+			v:        certificate,
+			data:     current,
+			ca:       otherCA,
+			expected: true,
+		},
+		{
+			name:     "unparseable certificate",
+			v:        certificate,
+			data:     map[string][]byte{"certificate": []byte("not a certificate")},
+			ca:       ca,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if due := dueForRenewal(test.v, test.data, test.ca); due != test.expected {
+				t.Errorf("expected due for renewal to be %t, got %t", test.expected, due)
+			}
+		})
+	}
+}
+
+func TestRenewalTime(t *testing.T) {
+	notBefore := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		expected time.Time
+	}{
+		{
+			name:     "a third of a short lifetime before expiry",
+			lifetime: 30 * 24 * time.Hour,
+			expected: notBefore.Add(20 * 24 * time.Hour),
+		},
+		{
+			name:     "at most 30 days before expiry",
+			lifetime: 365 * 24 * time.Hour,
+			expected: notBefore.Add(335 * 24 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cert := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(test.lifetime)}
+
+			if renewAt := renewalTime(cert); !renewAt.Equal(test.expected) {
+				t.Errorf("expected renewal at %s, got %s", test.expected, renewAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// ReleaseIndex is where the versions of a Release whose version is "latest"
+// or a constraint are looked up: a bosh.io-compatible index, defaulting to
+// the controller's --index-url, and the release's repository in that index,
+// e.g. github.com/cppforlife/zookeeper-release
+type ReleaseIndex struct {
+	URL        string `json:"url,omitempty"`
+	Repository string `json:"repository"`
+}
+
+// BaseImageIndex is the bosh.io-compatible index where the versions of a
+// BaseImage whose version is "latest" or a constraint are looked up,
+// defaulting to the controller's --index-url
+type BaseImageIndex struct {
+	URL string `json:"url,omitempty"`
+}
+
+// VersionRecord is a version a Release or BaseImage has resolved to, and
+// when
+type VersionRecord struct {
+	Version      string      `json:"version"`
+	ResolvedTime metav1.Time `json:"resolvedTime"`
+}
+
+// maxVersionHistory bounds the history shown in a resource's status; the
+// versions to delete are recorded separately, and never truncated.
+const maxVersionHistory = 10
+
+// recordVersion appends version to history unless it is already the most
+// recent version, keeping only the most recent versions.
+func recordVersion(history []VersionRecord, version string) []VersionRecord {
+	if n := len(history); n > 0 && history[n-1].Version == version {
+		return history
+	}
+
+	history = append(history, VersionRecord{Version: version, ResolvedTime: metav1.Now()})
+
+	if n := len(history); n > maxVersionHistory {
+		history = history[n-maxVersionHistory:]
+	}
+
+	return history
+}
+
+// recordUploadedVersion adds version to the versions which may have been
+// uploaded to the Director, unless it is already one of them.
+func recordUploadedVersion(uploaded []string, version string) []string {
+	if containsString(uploaded, version) {
+		return uploaded
+	}
+
+	return append(uploaded, version)
+}
+
+// uploadedVersions lists the current version, every recorded uploaded
+// version, and every version in history, each once, as the versions which may
+// have been uploaded to the Director. History still covers versions resolved
+// to before uploaded versions were recorded.
+func uploadedVersions(current string, uploaded []string, history []VersionRecord) []string {
+	var versions []string
+	if current != "" {
+		versions = append(versions, current)
+	}
+
+	for _, version := range uploaded {
+		if !containsString(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	for _, record := range history {
+		if !containsString(versions, record.Version) {
+			versions = append(versions, record.Version)
+		}
+	}
+
+	return versions
+}
+
+// latestIndexedVersion finds the newest version in an index's versions which
+// satisfies constraint.
+func latestIndexedVersion(
+	versions []remoteclients.IndexedVersion,
+	constraint string,
+	name string,
+) (remoteclients.IndexedVersion, error) {
+	version, found, err := remoteclients.LatestMatchingVersion(versions, constraint)
+	if err != nil {
+		return remoteclients.IndexedVersion{}, err
+	} else if !found {
+		return remoteclients.IndexedVersion{}, fmt.Errorf("no version of %s in index matches %q", name, constraint)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// VMTypeSpec defines the desired state of VMType
+// +kubebuilder:subresource:status
+type VMTypeSpec struct {
+	CloudProperties *runtime.RawExtension `json:"cloud_properties"`
+}
+
+// VMTypeStatus defines the observed state of VMType
+type VMTypeStatus struct {
+	Warning                 string                `json:"warning"`
+	OriginalCloudProperties *runtime.RawExtension `json:"cloud_properties"`
+	Available               bool                  `json:"available"`
+	Failure                 *Failure              `json:"failure,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VMType is the Schema for the vmtypes API
+type VMType struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VMTypeSpec   `json:"spec,omitempty"`
+	Status VMTypeStatus `json:"status,omitempty"`
+}
+
+func (v VMType) BeingDeleted() bool {
+	return !v.GetDeletionTimestamp().IsZero()
+}
+
+var vmTypeFinalizer = strings.Join([]string{"vmtype", finalizerBase}, ".")
+
+func (v VMType) hasFinalizer() bool {
+	return containsString(v.GetFinalizers(), vmTypeFinalizer)
+}
+
+func (v *VMType) EnsureFinalizer() bool {
+	changed := !v.hasFinalizer()
+	v.SetFinalizers(append(v.GetFinalizers(), vmTypeFinalizer))
+	return changed
+}
+
+func (v *VMType) EnsureNoFinalizer() bool {
+	changed := v.hasFinalizer()
+	v.SetFinalizers(removeString(v.GetFinalizers(), vmTypeFinalizer))
+	return changed
+}
+
+func (v VMType) LastFailure() *Failure {
+	return v.Status.Failure
+}
+
+func (v *VMType) RecordFailure(f *Failure) {
+	v.Status.Failure = f
+}
+
+func (v *VMType) PrepareToSave() (needsStatusUpdate bool) {
+	originalCloudProperties := v.Status.OriginalCloudProperties
+
+	if originalCloudProperties == nil {
+		v.Status.OriginalCloudProperties = v.Spec.CloudProperties
+		needsStatusUpdate = true
+	} else {
+		mutated := v.Spec.CloudProperties.String() != originalCloudProperties.String()
+
+		if mutated && v.Status.Warning == "" {
+			v.Status.Warning = resourceMutationWarning
+			needsStatusUpdate = true
+		} else if !mutated && v.Status.Warning != "" {
+			v.Status.Warning = ""
+			needsStatusUpdate = true
+		}
+	}
+
+	return
+}
+
+func (v VMType) InternalName() string {
+	return strings.Join([]string{
+		"vmtype",
+		v.GetNamespace(),
+		v.GetName(),
+	}, "-")
+}
+
+func (v *VMType) CreateUnlessExists(
+	bc remoteclients.BOSHClient,
+	_ context.Context,
+	_ client.Client,
+) error {
+	if err := bc.CreateVMType(
+		v.InternalName(),
+		remoteclients.VMType{
+			Name:            v.InternalName(),
+			CloudProperties: v.Status.OriginalCloudProperties,
+		},
+	); err != nil {
+		return err
+	}
+
+	v.Status.Available = true
+
+	return nil
+}
+
+func (v VMType) DeleteIfExists(bc remoteclients.BOSHClient) error {
+	return bc.DeleteVMType(v.InternalName())
+}
+
+// +kubebuilder:object:root=true
+
+// VMTypeList contains a list of VMType
+type VMTypeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VMType `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VMType{}, &VMTypeList{})
+}
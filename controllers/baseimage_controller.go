@@ -18,8 +18,10 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,12 +34,14 @@ type BaseImageReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+	IndexPollInterval   time.Duration
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=baseimages,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=baseimages/status,verbs=get;update;patch
 
-func (r *BaseImageReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+func (r *BaseImageReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
 	ctx := context.Background()
 	log := r.Log.WithValues("base_image", req.NamespacedName)
 
@@ -53,6 +57,7 @@ func (r *BaseImageReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err er
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		r.BOSHSystemNamespace,
 		req.NamespacedName.Namespace,
 	); err != nil {
@@ -61,15 +66,28 @@ func (r *BaseImageReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err er
 	}
 
 	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &baseImage); err != nil {
-		log.Error(err, "unable to reconcile with BOSH")
-		return
+		return retryAfterFailure(ctx, log, r.Client, &baseImage, err)
+	}
+
+	if baseImage.TracksIndex() && !baseImage.BeingDeleted() {
+		result.RequeueAfter = r.IndexPollInterval
 	}
 
 	return
 }
 
 func (r *BaseImageReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.BaseImage{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.BaseImage{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.BaseImageList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
@@ -20,12 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	boshv1 "github.com/amitkgupta/boshv3/api/v1"
@@ -38,9 +40,28 @@ type extensional interface {
 	EnsureFinalizer() bool
 }
 
+type failable interface {
+	GetGeneration() int64
+	LastFailure() *boshv1.Failure
+	RecordFailure(*boshv1.Failure)
+}
+
+// queueable resources report, as a condition, when their uploads or deploys
+// are waiting on the Director's concurrency limit.
+type queueable interface {
+	SetQueued(error)
+}
+
+// lockable resources report, as a condition, when their deploys are waiting
+// for another BOSH task to release its lock.
+type lockable interface {
+	SetLocked(error)
+}
+
 type boshArtifact interface {
 	runtime.Object
 	extensional
+	failable
 	PrepareToSave() bool
 	CreateUnlessExists(remoteclients.BOSHClient, context.Context, client.Client) error
 	DeleteIfExists(remoteclients.BOSHClient) error
@@ -49,6 +70,7 @@ type boshArtifact interface {
 type uaaEntity interface {
 	runtime.Object
 	extensional
+	failable
 	PrepareToSave(string) bool
 	SecretName() string
 	SecretNamespace() string
@@ -60,6 +82,7 @@ func boshClientForNamespace(
 	ctx context.Context,
 	log logr.Logger,
 	c client.Client,
+	cache *remoteclients.ClientCache,
 	boshSystemNamespace string,
 	namespace string,
 ) (remoteclients.BOSHClient, error) {
@@ -118,13 +141,22 @@ func boshClientForNamespace(
 		return nil, err
 	}
 
-	return remoteclients.NewBOSHClient(
+	creds, err := director.Credentials(ctx, c)
+	if err != nil {
+		log.Error(err, "failed to resolve director credentials", "director", director.GetName())
+		return nil, err
+	}
+
+	return cache.BOSHClient(
+		director.GetName(),
 		director.Spec.URL,
-		director.Spec.CACert,
+		creds.CACert,
 		director.Spec.UAAURL,
 		team.ClientName(),
 		string(secret.Data["secret"]),
-		director.Spec.UAACACert,
+		creds.UAACACert,
+		creds.Proxy,
+		director.Spec.MaxConcurrentOperations,
 	)
 }
 
@@ -132,6 +164,7 @@ func boshClientForDirector(
 	ctx context.Context,
 	log logr.Logger,
 	c client.Client,
+	cache *remoteclients.ClientCache,
 	boshSystemNamespace string,
 	directorName string,
 ) (remoteclients.BOSHClient, error) {
@@ -189,13 +222,22 @@ func boshClientForDirector(
 		return nil, err
 	}
 
-	return remoteclients.NewBOSHClient(
+	creds, err := director.Credentials(ctx, c)
+	if err != nil {
+		log.Error(err, "failed to resolve director credentials")
+		return nil, err
+	}
+
+	return cache.BOSHClient(
+		director.GetName(),
 		director.Spec.URL,
-		director.Spec.CACert,
+		creds.CACert,
 		director.Spec.UAAURL,
 		team.ClientName(),
 		string(secret.Data["secret"]),
-		director.Spec.UAACACert,
+		creds.UAACACert,
+		creds.Proxy,
+		director.Spec.MaxConcurrentOperations,
 	)
 }
 
@@ -208,7 +250,9 @@ func reconcileWithBOSH(
 ) error {
 	if ba.BeingDeleted() {
 		if err := ba.DeleteIfExists(bc); err != nil {
-			log.Error(err, "failed to delete if exists in BOSH")
+			if !waiting(err) {
+				log.Error(err, "failed to delete if exists in BOSH")
+			}
 			return err
 		}
 
@@ -222,6 +266,11 @@ func reconcileWithBOSH(
 		return nil
 	}
 
+	if ba.LastFailure().Permanent(ba.GetGeneration()) {
+		log.Info("not retrying after permanent failure until spec changes", "failure", ba.LastFailure().Message)
+		return nil
+	}
+
 	if ba.PrepareToSave() {
 		if err := c.Status().Update(ctx, ba); err != nil {
 			log.Error(err, "failed to updated after preparing to save")
@@ -237,10 +286,20 @@ func reconcileWithBOSH(
 	}
 
 	if err := ba.CreateUnlessExists(bc, ctx, c); err != nil {
-		log.Error(err, "failed to create unless exists in BOSH")
+		if !waiting(err) {
+			log.Error(err, "failed to create unless exists in BOSH")
+		}
 		return err
 	}
 
+	ba.RecordFailure(nil)
+	if q, ok := ba.(queueable); ok {
+		q.SetQueued(nil)
+	}
+	if l, ok := ba.(lockable); ok {
+		l.SetLocked(nil)
+	}
+
 	if err := c.Status().Update(ctx, ba); err != nil {
 		log.Error(err, "failed to update after creating unless exits in BOSH")
 		return err
@@ -248,3 +307,103 @@ func reconcileWithBOSH(
 
 	return nil
 }
+
+const (
+	failureBackoffBase    = 5 * time.Second
+	failureBackoffMax     = 10 * time.Minute
+	queuedRequeueInterval = 15 * time.Second
+	lockedRequeueInterval = 30 * time.Second
+	taskPollInterval      = 10 * time.Second
+)
+
+type failableObject interface {
+	runtime.Object
+	extensional
+	failable
+}
+
+// retryAfterFailure records a failure to reconcile with BOSH or UAA in the
+// resource's status. Transient and auth failures are retried with capped
+// exponential backoff, while permanent failures are not retried until the
+// resource's spec changes, unless it is being deleted. Uploads and deploys
+// which were not started because the Director is busy are not failures, and
+// are instead marked as queued and retried shortly, and likewise deploys which
+// were not started because another BOSH task holds the deployment's lock are
+// marked as locked. Resources whose BOSH tasks are still running are checked
+// on again shortly.
+func retryAfterFailure(
+	ctx context.Context,
+	log logr.Logger,
+	c client.Client,
+	fo failableObject,
+	err error,
+) (ctrl.Result, error) {
+	if q, ok := fo.(queueable); ok && remoteclients.IsDirectorBusy(err) {
+		q.SetQueued(err)
+
+		if err := c.Status().Update(ctx, fo); err != nil {
+			log.Error(err, "failed to update after queueing")
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: queuedRequeueInterval}, nil
+	}
+
+	if remoteclients.IsTaskRunning(err) {
+		if err := c.Status().Update(ctx, fo); err != nil {
+			log.Error(err, "failed to update after starting task")
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: taskPollInterval}, nil
+	}
+
+	if l, ok := fo.(lockable); ok && remoteclients.IsDeploymentLocked(err) {
+		l.SetLocked(err)
+
+		if err := c.Status().Update(ctx, fo); err != nil {
+			log.Error(err, "failed to update after finding lock")
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{RequeueAfter: lockedRequeueInterval}, nil
+	}
+
+	log.Error(err, "unable to reconcile")
+
+	failure := boshv1.NewFailure(fo.LastFailure(), fo.GetGeneration(), err)
+	fo.RecordFailure(failure)
+
+	if err := c.Status().Update(ctx, fo); err != nil {
+		log.Error(err, "failed to update after recording failure")
+		return ctrl.Result{}, err
+	}
+
+	if failure.Class == remoteclients.PermanentErrorClass && !fo.BeingDeleted() {
+		log.Info("not retrying after permanent failure until spec changes", "failure", failure.Message)
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: failureBackoff(failure.Attempts)}, nil
+}
+
+// waiting is true for errors which mean work is waiting on the Director,
+// rather than having failed.
+func waiting(err error) bool {
+	return remoteclients.IsDirectorBusy(err) ||
+		remoteclients.IsDeploymentLocked(err) ||
+		remoteclients.IsTaskRunning(err)
+}
+
+func failureBackoff(attempts int32) time.Duration {
+	backoff := failureBackoffBase
+	for i := int32(1); i < attempts && backoff < failureBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	if backoff > failureBackoffMax {
+		return failureBackoffMax
+	}
+
+	return backoff
+}
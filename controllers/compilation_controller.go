@@ -21,6 +21,7 @@ import (
 	"errors"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -33,6 +34,7 @@ type CompilationReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=compilations,verbs=get;list;watch;create;update;patch;delete
@@ -73,6 +75,7 @@ func (r *CompilationReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		r.BOSHSystemNamespace,
 		compilation.Status.OriginalDirector,
 	); err != nil {
@@ -85,15 +88,24 @@ func (r *CompilationReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err
 	}
 
 	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &compilation); err != nil {
-		log.Error(err, "unable to reconcile with BOSH")
-		return
+		return retryAfterFailure(ctx, log, r.Client, &compilation, err)
 	}
 
 	return
 }
 
 func (r *CompilationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Compilation{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Compilation{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.CompilationList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// CompiledReleaseReconciler reconciles a CompiledRelease object
+type CompiledReleaseReconciler struct {
+	client.Client
+	Log                 logr.Logger
+	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+}
+
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=compiledreleases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=compiledreleases/status,verbs=get;update;patch
+
+func (r *CompiledReleaseReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("compiled_release", req.NamespacedName)
+
+	var compiledRelease boshv1.CompiledRelease
+	if err = r.Get(ctx, req.NamespacedName, &compiledRelease); err != nil {
+		log.Error(err, "unable to fetch compiled release")
+		err = ignoreDoesNotExist(err)
+		return
+	}
+
+	var bc remoteclients.BOSHClient
+	if bc, err = boshClientForNamespace(
+		ctx,
+		log,
+		r.Client,
+		r.ClientCache,
+		r.BOSHSystemNamespace,
+		req.NamespacedName.Namespace,
+	); err != nil {
+		log.Error(err, "unable to construct BOSH client for namespace", "namespace", req.NamespacedName.Namespace)
+		return
+	}
+
+	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &compiledRelease); err != nil {
+		return retryAfterFailure(ctx, log, r.Client, &compiledRelease, err)
+	}
+
+	return
+}
+
+func (r *CompiledReleaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.CompiledRelease{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.CompiledReleaseList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
+		Complete(r)
+}
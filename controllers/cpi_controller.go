@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// CPIReconciler reconciles a CPI object
+type CPIReconciler struct {
+	client.Client
+	Log                 logr.Logger
+	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+}
+
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=cpis,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=cpis/status,verbs=get;update;patch
+
+func (r *CPIReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("cpi", req.NamespacedName)
+
+	if req.NamespacedName.Namespace != r.BOSHSystemNamespace {
+		msg := "cannot create cpi outside BOSH system namespace"
+		err = errors.New(msg)
+		log.Error(
+			err,
+			msg,
+			"namespace", req.NamespacedName.Namespace,
+			"bosh_system_namespace", r.BOSHSystemNamespace,
+		)
+		return
+	}
+
+	var cpi boshv1.CPI
+	if err = r.Get(ctx, req.NamespacedName, &cpi); err != nil {
+		log.Error(err, "unable to fetch cpi")
+		err = ignoreDoesNotExist(err)
+		return
+	}
+
+	if cpi.PrepareToSave() {
+		if err = r.Status().Update(ctx, &cpi); err != nil {
+			log.Error(err, "unable to save cpi")
+			return
+		}
+	}
+
+	var bc remoteclients.BOSHClient
+	if bc, err = boshClientForDirector(
+		ctx,
+		log,
+		r.Client,
+		r.ClientCache,
+		r.BOSHSystemNamespace,
+		cpi.Status.OriginalDirector,
+	); err != nil {
+		log.Error(
+			err,
+			"unable to construct BOSH client for director",
+			"director", cpi.Status.OriginalDirector,
+		)
+		return
+	}
+
+	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &cpi); err != nil {
+		return retryAfterFailure(ctx, log, r.Client, &cpi, err)
+	}
+
+	return
+}
+
+func (r *CPIReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return watchCPIPropertySources(
+		watchDirectorCredentials(
+			ctrl.NewControllerManagedBy(mgr).For(&boshv1.CPI{}),
+			mgr.GetClient(),
+			r.Log,
+			r.BOSHSystemNamespace,
+			requestsForTenantsOfDirectors(
+				mgr.GetClient(),
+				r.Log,
+				func() runtime.Object { return &boshv1.CPIList{} },
+			),
+		),
+		mgr.GetClient(),
+		r.Log,
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
+		Complete(r)
+}
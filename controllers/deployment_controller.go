@@ -18,8 +18,11 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,12 +35,13 @@ type DeploymentReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=deployments/status,verbs=get;update;patch
 
-func (r *DeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+func (r *DeploymentReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
 	ctx := context.Background()
 	log := r.Log.WithValues("deployment", req.NamespacedName)
 
@@ -61,6 +65,7 @@ func (r *DeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err e
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		r.BOSHSystemNamespace,
 		req.NamespacedName.Namespace,
 	); err != nil {
@@ -69,15 +74,42 @@ func (r *DeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err e
 	}
 
 	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &deployment); err != nil {
-		log.Error(err, "unable to reconcile with BOSH")
-		return
+		return retryAfterFailure(ctx, log, r.Client, &deployment, err)
+	}
+
+	if renewAt := deployment.Status.VariablesRenewTime; renewAt != nil && !deployment.BeingDeleted() {
+		result.RequeueAfter = time.Until(renewAt.Time)
+		if result.RequeueAfter < 0 {
+			result.RequeueAfter = 0
+			result.Requeue = true
+		}
 	}
 
 	return
 }
 
 func (r *DeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Deployment{}).
+	return watchDirectorCredentials(
+		watchRolePropertySources(
+			watchNewVersions(
+				ctrl.NewControllerManagedBy(mgr).
+					For(&boshv1.Deployment{}).
+					Owns(&v1.Secret{}),
+				mgr.GetClient(),
+				r.Log,
+			),
+			mgr.GetClient(),
+			r.Log,
+		),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.DeploymentList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdatesExceptNewVersions).
 		Complete(r)
 }
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -26,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+	"github.com/amitkgupta/boshv3/remote-clients"
 )
 
 // DirectorReconciler reconciles a Director object
@@ -33,12 +35,14 @@ type DirectorReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+	HealthCheckInterval time.Duration
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=directors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=directors/status,verbs=get;update;patch
 
-func (r *DirectorReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+func (r *DirectorReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
 	ctx := context.Background()
 	log := r.Log.WithValues("director", req.NamespacedName)
 
@@ -91,11 +95,71 @@ func (r *DirectorReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err err
 		return
 	}
 
+	if healthErr := r.checkHealth(ctx, log, director); healthErr != nil {
+		log.Error(healthErr, "director is unhealthy")
+	}
+
+	if err = r.Status().Update(ctx, director); err != nil {
+		log.Error(err, "failed to update after checking health")
+		return
+	}
+
+	result.RequeueAfter = r.HealthCheckInterval
 	return
 }
 
+func (r *DirectorReconciler) checkHealth(ctx context.Context, log logr.Logger, director *boshv1.Director) error {
+	creds, err := director.Credentials(ctx, r.Client)
+	if err != nil {
+		return director.HealthCheckFailed(err)
+	}
+
+	bc, err := r.ClientCache.BOSHClient(
+		director.GetName(),
+		director.Spec.URL,
+		creds.CACert,
+		director.Spec.UAAURL,
+		creds.UAAClient,
+		creds.UAAClientSecret,
+		creds.UAACACert,
+		creds.Proxy,
+		director.Spec.MaxConcurrentOperations,
+	)
+	if err != nil {
+		return director.HealthCheckFailed(err)
+	}
+
+	uc, err := r.ClientCache.UAAClient(
+		director.GetName(),
+		director.Spec.UAAURL,
+		creds.UAAClient,
+		creds.UAAClientSecret,
+		creds.UAACACert,
+		creds.Proxy,
+	)
+	if err != nil {
+		return director.HealthCheckFailed(err)
+	}
+
+	if err := director.CheckHealth(bc, uc); err != nil {
+		return err
+	}
+
+	if err := director.CleanUpOrphanedDisks(bc); err != nil {
+		log.Error(err, "failed to clean up orphaned disks")
+	}
+
+	return nil
+}
+
 func (r *DirectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Director{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Director{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForDirectors,
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,6 +33,7 @@ type ExtensionReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=extensions,verbs=get;list;watch;create;update;patch;delete
@@ -53,6 +55,7 @@ func (r *ExtensionReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err er
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		r.BOSHSystemNamespace,
 		req.NamespacedName.Namespace,
 	); err != nil {
@@ -61,15 +64,24 @@ func (r *ExtensionReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err er
 	}
 
 	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &extension); err != nil {
-		log.Error(err, "unable to reconcile with BOSH")
-		return
+		return retryAfterFailure(ctx, log, r.Client, &extension, err)
 	}
 
 	return
 }
 
 func (r *ExtensionReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Extension{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Extension{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.ExtensionList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
@@ -18,8 +18,14 @@ package controllers
 
 import (
 	"crypto/rand"
+	"reflect"
 
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
 )
 
 func ignoreDoesNotExist(err error) error {
@@ -36,6 +42,60 @@ func ignoreAlreadyExists(err error) error {
 	return err
 }
 
+// ignoreStatusOnlyUpdates filters out updates that don't change an object's
+// generation, so that reconcilers which record observations in status on
+// every pass don't immediately trigger themselves again. Objects that don't
+// track a generation, such as Secrets and ConfigMaps, are never filtered.
+var ignoreStatusOnlyUpdates = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.MetaNew.GetGeneration() == 0 ||
+			e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+			!e.MetaNew.GetDeletionTimestamp().IsZero()
+	},
+}
+
+// ignoreStatusOnlyUpdatesExceptNewVersions is like ignoreStatusOnlyUpdates,
+// but also lets through status updates which resolve a Release or BaseImage
+// to a new version, for Deployments which roll forward and for Addons.
+var ignoreStatusOnlyUpdatesExceptNewVersions = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return ignoreStatusOnlyUpdates.Update(e) || resolvedNewVersion(e.ObjectOld, e.ObjectNew)
+	},
+}
+
+func resolvedNewVersion(old, new runtime.Object) bool {
+	switch o := old.(type) {
+	case *boshv1.Release:
+		n, ok := new.(*boshv1.Release)
+		return ok && o.BOSHVersion() != n.BOSHVersion()
+	case *boshv1.BaseImage:
+		n, ok := new.(*boshv1.BaseImage)
+		return ok && o.BOSHVersion() != n.BOSHVersion()
+	default:
+		return false
+	}
+}
+
+// ignoreStatusOnlyUpdatesExceptNewJobs is like ignoreStatusOnlyUpdates, but
+// also lets through status updates which change the jobs read back for a
+// Release, e.g. when it first becomes available or resolves to a new
+// version, so that Roles using it are validated again.
+var ignoreStatusOnlyUpdatesExceptNewJobs = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return ignoreStatusOnlyUpdates.Update(e) || changedJobs(e.ObjectOld, e.ObjectNew)
+	},
+}
+
+func changedJobs(old, new runtime.Object) bool {
+	o, ok := old.(*boshv1.Release)
+	if !ok {
+		return false
+	}
+
+	n, ok := new.(*boshv1.Release)
+	return ok && !reflect.DeepEqual(o.Status.Jobs, n.Status.Jobs)
+}
+
 func generateSecret() (string, error) {
 	bytes := make([]byte, 20)
 	if _, err := rand.Read(bytes); err != nil {
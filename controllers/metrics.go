@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+)
+
+var availabilityDesc = prometheus.NewDesc(
+	"boshv3_resources",
+	"Number of resources of each kind, by whether they are available. A Director is available when it is reachable and its UAA admin credentials are valid.",
+	[]string{"kind", "available"},
+	nil,
+)
+
+// availabilityCollector counts available and unavailable resources of each
+// kind from the manager's cache whenever metrics are scraped, so that the
+// counts never go stale as resources are deleted.
+type availabilityCollector struct {
+	client client.Client
+	log    logr.Logger
+}
+
+func NewAvailabilityCollector(c client.Client, log logr.Logger) prometheus.Collector {
+	return availabilityCollector{client: c, log: log}
+}
+
+var availabilityKinds = []struct {
+	kind    string
+	newList func() runtime.Object
+}{
+	{"Director", func() runtime.Object { return &boshv1.DirectorList{} }},
+	{"Team", func() runtime.Object { return &boshv1.TeamList{} }},
+	{"Release", func() runtime.Object { return &boshv1.ReleaseList{} }},
+	{"BaseImage", func() runtime.Object { return &boshv1.BaseImageList{} }},
+	{"Extension", func() runtime.Object { return &boshv1.ExtensionList{} }},
+	{"VMType", func() runtime.Object { return &boshv1.VMTypeList{} }},
+	{"DiskType", func() runtime.Object { return &boshv1.DiskTypeList{} }},
+	{"AZ", func() runtime.Object { return &boshv1.AZList{} }},
+	{"Network", func() runtime.Object { return &boshv1.NetworkList{} }},
+	{"Compilation", func() runtime.Object { return &boshv1.CompilationList{} }},
+	{"Deployment", func() runtime.Object { return &boshv1.DeploymentList{} }},
+	{"CompiledRelease", func() runtime.Object { return &boshv1.CompiledReleaseList{} }},
+	{"Addon", func() runtime.Object { return &boshv1.AddonList{} }},
+	{"CPI", func() runtime.Object { return &boshv1.CPIList{} }},
+}
+
+func (a availabilityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- availabilityDesc
+}
+
+func (a availabilityCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, k := range availabilityKinds {
+		list := k.newList()
+		if err := a.client.List(context.Background(), list); err != nil {
+			a.log.Error(err, "failed to list resources for metrics", "kind", k.kind)
+			continue
+		}
+
+		objs, err := apimeta.ExtractList(list)
+		if err != nil {
+			a.log.Error(err, "failed to extract list for metrics", "kind", k.kind)
+			continue
+		}
+
+		counts := map[bool]int{true: 0, false: 0}
+		for _, obj := range objs {
+			counts[isAvailable(obj)]++
+		}
+
+		for available, count := range counts {
+			ch <- prometheus.MustNewConstMetric(
+				availabilityDesc,
+				prometheus.GaugeValue,
+				float64(count),
+				k.kind,
+				strconv.FormatBool(available),
+			)
+		}
+	}
+}
+
+func isAvailable(obj runtime.Object) bool {
+	switch o := obj.(type) {
+	case *boshv1.Director:
+		return o.Status.Reachable && o.Status.UAAAuthenticated
+	case *boshv1.Team:
+		return o.Status.Available
+	case *boshv1.Release:
+		return o.Status.Available
+	case *boshv1.BaseImage:
+		return o.Status.Available
+	case *boshv1.Extension:
+		return o.Status.Available
+	case *boshv1.VMType:
+		return o.Status.Available
+	case *boshv1.DiskType:
+		return o.Status.Available
+	case *boshv1.AZ:
+		return o.Status.Available
+	case *boshv1.Network:
+		return o.Status.Available
+	case *boshv1.Compilation:
+		return o.Status.Available
+	case *boshv1.Deployment:
+		return o.Status.Available
+	case *boshv1.CompiledRelease:
+		return o.Status.Available
+	case *boshv1.Addon:
+		return o.Status.Available
+	case *boshv1.CPI:
+		return o.Status.Available
+	default:
+		return false
+	}
+}
@@ -18,8 +18,10 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,12 +34,15 @@ type ReleaseReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+	GitPollInterval     time.Duration
+	IndexPollInterval   time.Duration
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=releases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=releases/status,verbs=get;update;patch
 
-func (r *ReleaseReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+func (r *ReleaseReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
 	ctx := context.Background()
 	log := r.Log.WithValues("release", req.NamespacedName)
 
@@ -53,6 +58,7 @@ func (r *ReleaseReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err erro
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		r.BOSHSystemNamespace,
 		req.NamespacedName.Namespace,
 	); err != nil {
@@ -61,15 +67,34 @@ func (r *ReleaseReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err erro
 	}
 
 	if err = reconcileWithBOSH(ctx, log, r.Client, bc, &release); err != nil {
-		log.Error(err, "unable to reconcile with BOSH")
+		return retryAfterFailure(ctx, log, r.Client, &release, err)
+	}
+
+	if release.BeingDeleted() {
 		return
 	}
 
+	if release.TracksGitRef() {
+		result.RequeueAfter = r.GitPollInterval
+	} else if release.TracksIndex() {
+		result.RequeueAfter = r.IndexPollInterval
+	}
+
 	return
 }
 
 func (r *ReleaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Release{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Release{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTenantsOfDirectors(
+			mgr.GetClient(),
+			r.Log,
+			func() runtime.Object { return &boshv1.ReleaseList{} },
+		),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
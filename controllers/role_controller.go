@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+	"github.com/amitkgupta/boshv3/remote-clients"
+)
+
+// RoleReconciler reconciles a Role object
+type RoleReconciler struct {
+	client.Client
+	Log                 logr.Logger
+	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
+}
+
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=roles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=roles/status,verbs=get;update;patch
+
+func (r *RoleReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("role", req.NamespacedName)
+
+	var role boshv1.Role
+	if err = r.Get(ctx, req.NamespacedName, &role); err != nil {
+		log.Error(err, "unable to fetch role")
+		err = ignoreDoesNotExist(err)
+		return
+	}
+
+	var bc remoteclients.BOSHClient
+	if bc, err = boshClientForNamespace(
+		ctx,
+		log,
+		r.Client,
+		r.ClientCache,
+		r.BOSHSystemNamespace,
+		req.NamespacedName.Namespace,
+	); err != nil {
+		log.Error(err, "unable to construct BOSH client for namespace", "namespace", req.NamespacedName.Namespace)
+		return
+	}
+
+	if err = role.Validate(bc, ctx, r.Client); err != nil {
+		log.Error(err, "unable to validate against release job spec")
+		return
+	}
+
+	if err = r.Status().Update(ctx, &role); err != nil {
+		log.Error(err, "unable to save role")
+		return
+	}
+
+	return
+}
+
+func (r *RoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return watchReleaseJobs(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Role{}),
+		mgr.GetClient(),
+		r.Log,
+	).
+		WithEventFilter(ignoreStatusOnlyUpdatesExceptNewJobs).
+		Complete(r)
+}
@@ -36,6 +36,7 @@ type TeamReconciler struct {
 	client.Client
 	Log                 logr.Logger
 	BOSHSystemNamespace string
+	ClientCache         *remoteclients.ClientCache
 }
 
 // +kubebuilder:rbac:groups=bosh.akgupta.ca,resources=teams,verbs=get;list;watch;create;update;patch;delete
@@ -64,6 +65,7 @@ func (r *TeamReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error)
 		ctx,
 		log,
 		r.Client,
+		r.ClientCache,
 		team.Status.OriginalDirector,
 		r.BOSHSystemNamespace,
 	); err != nil {
@@ -76,16 +78,21 @@ func (r *TeamReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, err error)
 	}
 
 	if err = reconcileWithUAA(ctx, log, r.Client, uc, &team); err != nil {
-		log.Error(err, "unable to reconcile with UAA")
-		return
+		return retryAfterFailure(ctx, log, r.Client, &team, err)
 	}
 
 	return
 }
 
 func (r *TeamReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&boshv1.Team{}).
+	return watchDirectorCredentials(
+		ctrl.NewControllerManagedBy(mgr).For(&boshv1.Team{}),
+		mgr.GetClient(),
+		r.Log,
+		r.BOSHSystemNamespace,
+		requestsForTeamsOfDirectors(mgr.GetClient(), r.Log),
+	).
+		WithEventFilter(ignoreStatusOnlyUpdates).
 		Complete(r)
 }
 
@@ -93,6 +100,7 @@ func uaaAdminForDirector(
 	ctx context.Context,
 	log logr.Logger,
 	c client.Client,
+	cache *remoteclients.ClientCache,
 	directorName string,
 	boshSystemNamespace string,
 ) (remoteclients.UAAClient, error) {
@@ -111,24 +119,19 @@ func uaaAdminForDirector(
 		return nil, err
 	}
 
-	var directorSecret v1.Secret
-	if err := c.Get(
-		ctx,
-		types.NamespacedName{
-			Namespace: boshSystemNamespace,
-			Name:      director.Spec.UAAClientSecret,
-		},
-		&directorSecret,
-	); err != nil {
-		log.Error(err, "failed to get secret", "secret", director.Spec.UAAClientSecret)
+	creds, err := director.Credentials(ctx, c)
+	if err != nil {
+		log.Error(err, "failed to resolve director credentials")
 		return nil, err
 	}
 
-	return remoteclients.NewUAAClient(
+	return cache.UAAClient(
+		director.GetName(),
 		director.Spec.UAAURL,
-		director.Spec.UAAClient,
-		string(directorSecret.Data["secret"]),
-		director.Spec.UAACACert,
+		creds.UAAClient,
+		creds.UAAClientSecret,
+		creds.UAACACert,
+		creds.Proxy,
 	)
 }
 
@@ -165,6 +168,11 @@ func reconcileWithUAA(
 		return nil
 	}
 
+	if ue.LastFailure().Permanent(ue.GetGeneration()) {
+		log.Info("not retrying after permanent failure until spec changes", "failure", ue.LastFailure().Message)
+		return nil
+	}
+
 	if ue.EnsureFinalizer() {
 		if err := c.Update(ctx, ue); err != nil {
 			log.Error(err, "failed to update after ensuring finalizer")
@@ -198,6 +206,8 @@ func reconcileWithUAA(
 		return err
 	}
 
+	ue.RecordFailure(nil)
+
 	if err := c.Status().Update(ctx, ue); err != nil {
 		log.Error(err, "failed to update after creating unless exists in UAA")
 		return err
@@ -0,0 +1,478 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	boshv1 "github.com/amitkgupta/boshv3/api/v1"
+)
+
+type directorRequestsFunc func(context.Context, []boshv1.Director) []ctrl.Request
+
+// watchDirectorCredentials makes the controller being built re-reconcile
+// whenever a Secret or ConfigMap referenced by a Director changes, so that
+// rotating a CA certificate or credential doesn't require editing any custom
+// resources. The given function determines which requests to enqueue for the
+// affected Directors.
+func watchDirectorCredentials(
+	blder *ctrl.Builder,
+	c client.Client,
+	log logr.Logger,
+	boshSystemNamespace string,
+	requestsFor directorRequestsFunc,
+) *ctrl.Builder {
+	return blder.
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: directorReferencesMapper(
+					c, log, boshSystemNamespace, requestsFor, boshv1.Director.ReferencesSecret,
+				),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: directorReferencesMapper(
+					c, log, boshSystemNamespace, requestsFor, boshv1.Director.ReferencesConfigMap,
+				),
+			},
+		)
+}
+
+func directorReferencesMapper(
+	c client.Client,
+	log logr.Logger,
+	boshSystemNamespace string,
+	requestsFor directorRequestsFunc,
+	references func(boshv1.Director, string) bool,
+) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		if o.Meta.GetNamespace() != boshSystemNamespace {
+			return nil
+		}
+
+		ctx := context.Background()
+
+		var directors boshv1.DirectorList
+		if err := c.List(ctx, &directors, client.InNamespace(boshSystemNamespace)); err != nil {
+			log.Error(err, "failed to list directors")
+			return nil
+		}
+
+		var referencing []boshv1.Director
+		for _, d := range directors.Items {
+			if references(d, o.Meta.GetName()) {
+				referencing = append(referencing, d)
+			}
+		}
+
+		if len(referencing) == 0 {
+			return nil
+		}
+
+		return requestsFor(ctx, referencing)
+	}
+}
+
+func requestsForDirectors(_ context.Context, directors []boshv1.Director) []ctrl.Request {
+	requests := make([]ctrl.Request, len(directors))
+	for i, d := range directors {
+		requests[i] = ctrl.Request{NamespacedName: types.NamespacedName{
+			Namespace: d.GetNamespace(),
+			Name:      d.GetName(),
+		}}
+	}
+	return requests
+}
+
+func teamsOfDirectors(
+	ctx context.Context,
+	c client.Client,
+	directors []boshv1.Director,
+) ([]boshv1.Team, error) {
+	var teams boshv1.TeamList
+	if err := c.List(ctx, &teams); err != nil {
+		return nil, err
+	}
+
+	var result []boshv1.Team
+	for _, t := range teams.Items {
+		for _, d := range directors {
+			if t.Status.OriginalDirector == d.GetName() {
+				result = append(result, t)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func requestsForTeamsOfDirectors(c client.Client, log logr.Logger) directorRequestsFunc {
+	return func(ctx context.Context, directors []boshv1.Director) []ctrl.Request {
+		teams, err := teamsOfDirectors(ctx, c, directors)
+		if err != nil {
+			log.Error(err, "failed to list teams")
+			return nil
+		}
+
+		requests := make([]ctrl.Request, len(teams))
+		for i, t := range teams {
+			requests[i] = ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: t.GetNamespace(),
+				Name:      t.GetName(),
+			}}
+		}
+		return requests
+	}
+}
+
+// requestsForTenantsOfDirectors enqueues every object of a namespaced BOSH
+// kind whose namespace has a Team using one of the given Directors. The
+// newList function returns an empty list of that kind.
+func requestsForTenantsOfDirectors(
+	c client.Client,
+	log logr.Logger,
+	newList func() runtime.Object,
+) directorRequestsFunc {
+	return func(ctx context.Context, directors []boshv1.Director) []ctrl.Request {
+		teams, err := teamsOfDirectors(ctx, c, directors)
+		if err != nil {
+			log.Error(err, "failed to list teams")
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, t := range teams {
+			list := newList()
+			if err := c.List(ctx, list, client.InNamespace(t.GetNamespace())); err != nil {
+				log.Error(err, "failed to list tenant resources", "namespace", t.GetNamespace())
+				continue
+			}
+
+			objects, err := apimeta.ExtractList(list)
+			if err != nil {
+				log.Error(err, "failed to extract tenant resources", "namespace", t.GetNamespace())
+				continue
+			}
+
+			for _, o := range objects {
+				if m, err := apimeta.Accessor(o); err == nil {
+					requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+						Namespace: m.GetNamespace(),
+						Name:      m.GetName(),
+					}})
+				}
+			}
+		}
+		return requests
+	}
+}
+
+// watchNewVersions makes the Deployment controller re-reconcile Deployments
+// which roll forward whenever a Release or BaseImage they deploy resolves to
+// a new version.
+func watchNewVersions(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.
+		Watches(
+			&source.Kind{Type: &boshv1.Release{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: deploymentsRollingForwardWith(c, log, "Release"),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &boshv1.BaseImage{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: deploymentsRollingForwardWith(c, log, "BaseImage"),
+			},
+		)
+}
+
+func deploymentsRollingForwardWith(c client.Client, log logr.Logger, kind string) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		var deployments boshv1.DeploymentList
+		if err := c.List(
+			context.Background(),
+			&deployments,
+			client.InNamespace(o.Meta.GetNamespace()),
+		); err != nil {
+			log.Error(err, "failed to list deployments", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, d := range deployments.Items {
+			if d.RollsForwardWith(kind, o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: d.GetNamespace(),
+					Name:      d.GetName(),
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+// watchReleaseJobs makes the Role controller re-reconcile Roles whenever the
+// jobs of the Release they use change.
+func watchReleaseJobs(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.Watches(
+		&source.Kind{Type: &boshv1.Release{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: rolesUsingRelease(c, log)},
+	)
+}
+
+func rolesUsingRelease(c client.Client, log logr.Logger) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		var roles boshv1.RoleList
+		if err := c.List(
+			context.Background(),
+			&roles,
+			client.InNamespace(o.Meta.GetNamespace()),
+		); err != nil {
+			log.Error(err, "failed to list roles", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, r := range roles.Items {
+			if r.UsesRelease(o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: r.GetNamespace(),
+					Name:      r.GetName(),
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+// watchAddonReleases makes the Addon controller re-reconcile Addons whenever
+// a Release they use resolves to a new version, so that the runtime config
+// is updated with it.
+func watchAddonReleases(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.Watches(
+		&source.Kind{Type: &boshv1.Release{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: addonsUsingRelease(c, log)},
+	)
+}
+
+func addonsUsingRelease(c client.Client, log logr.Logger) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		var addons boshv1.AddonList
+		if err := c.List(
+			context.Background(),
+			&addons,
+			client.InNamespace(o.Meta.GetNamespace()),
+		); err != nil {
+			log.Error(err, "failed to list addons", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, a := range addons.Items {
+			if a.UsesRelease(o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: a.GetNamespace(),
+					Name:      a.GetName(),
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+// watchAddonPropertySources makes the Addon controller re-reconcile Addons
+// whenever a Secret or ConfigMap referenced by their jobs' properties
+// changes, so that the new value is posted to the Director.
+func watchAddonPropertySources(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: addonsReferencing(c, log, boshv1.Addon.ReferencesSecret),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: addonsReferencing(c, log, boshv1.Addon.ReferencesConfigMap),
+			},
+		)
+}
+
+func addonsReferencing(
+	c client.Client,
+	log logr.Logger,
+	references func(boshv1.Addon, string) bool,
+) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		var addons boshv1.AddonList
+		if err := c.List(
+			context.Background(),
+			&addons,
+			client.InNamespace(o.Meta.GetNamespace()),
+		); err != nil {
+			log.Error(err, "failed to list addons", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, a := range addons.Items {
+			if references(a, o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: a.GetNamespace(),
+					Name:      a.GetName(),
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+// watchRolePropertySources makes the Deployment controller re-reconcile
+// Deployments whenever a Secret or ConfigMap referenced by the properties of
+// one of their Roles, or by their own property overrides, changes, so that
+// the new value is deployed.
+func watchRolePropertySources(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: deploymentsReferencing(c, log, boshv1.Role.ReferencesSecret, boshv1.Deployment.ReferencesSecret),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: deploymentsReferencing(c, log, boshv1.Role.ReferencesConfigMap, boshv1.Deployment.ReferencesConfigMap),
+			},
+		)
+}
+
+func deploymentsReferencing(
+	c client.Client,
+	log logr.Logger,
+	references func(boshv1.Role, string) bool,
+	overridesReference func(boshv1.Deployment, string) bool,
+) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		ctx := context.Background()
+		namespace := o.Meta.GetNamespace()
+
+		var roles boshv1.RoleList
+		if err := c.List(ctx, &roles, client.InNamespace(namespace)); err != nil {
+			log.Error(err, "failed to list roles", "namespace", namespace)
+			return nil
+		}
+
+		var referencing []string
+		for _, r := range roles.Items {
+			if references(r, o.Meta.GetName()) {
+				referencing = append(referencing, r.GetName())
+			}
+		}
+
+		var deployments boshv1.DeploymentList
+		if err := c.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+			log.Error(err, "failed to list deployments", "namespace", namespace)
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, d := range deployments.Items {
+			if overridesReference(d, o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: d.GetNamespace(),
+					Name:      d.GetName(),
+				}})
+				continue
+			}
+
+			for _, role := range referencing {
+				if d.UsesRole(role) {
+					requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+						Namespace: d.GetNamespace(),
+						Name:      d.GetName(),
+					}})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
+
+// watchCPIPropertySources makes the CPI controller re-reconcile CPIs
+// whenever a Secret or ConfigMap referenced by their properties changes, so
+// that rotated IaaS credentials are posted to the Director.
+func watchCPIPropertySources(blder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return blder.
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: cpisReferencing(c, log, boshv1.CPI.ReferencesSecret),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: cpisReferencing(c, log, boshv1.CPI.ReferencesConfigMap),
+			},
+		)
+}
+
+func cpisReferencing(
+	c client.Client,
+	log logr.Logger,
+	references func(boshv1.CPI, string) bool,
+) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		var cpis boshv1.CPIList
+		if err := c.List(
+			context.Background(),
+			&cpis,
+			client.InNamespace(o.Meta.GetNamespace()),
+		); err != nil {
+			log.Error(err, "failed to list cpis", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+
+		var requests []ctrl.Request
+		for _, cpi := range cpis.Items {
+			if references(cpi, o.Meta.GetName()) {
+				requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: cpi.GetNamespace(),
+					Name:      cpi.GetName(),
+				}})
+			}
+		}
+		return requests
+	}
+}
@@ -19,13 +19,16 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	boshv1 "github.com/amitkgupta/boshv3/api/v1"
 	"github.com/amitkgupta/boshv3/controllers"
+	"github.com/amitkgupta/boshv3/remote-clients"
 	"k8s.io/apimachinery/pkg/runtime"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	// +kubebuilder:scaffold:imports
 
@@ -48,14 +51,38 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var directorHealthCheckInterval time.Duration
+	var clientCacheIdleTimeout time.Duration
+	var directorMaxConcurrentOperations int
+	var artifactsDir string
+	var gitPollInterval time.Duration
+	var indexURL string
+	var indexPollInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&directorHealthCheckInterval, "director-health-check-interval", time.Minute,
+		"How often each Director is checked for reachability and valid UAA admin credentials.")
+	flag.DurationVar(&clientCacheIdleTimeout, "client-cache-idle-timeout", time.Hour,
+		"How long a cached BOSH or UAA client, and its UAA token, may go unused before it is discarded.")
+	flag.IntVar(&directorMaxConcurrentOperations, "director-max-concurrent-operations", 0,
+		"How many release and stemcell uploads and deploys may run against each Director at once, unless set on the Director; 0 means no limit.")
+	flag.StringVar(&artifactsDir, "artifacts-dir", "/var/lib/boshv3/artifacts",
+		"The directory under which PersistentVolumeClaims holding release and stemcell tarballs are mounted, each at <namespace>/<claim name>, the namespace being that of the resource using the claim.")
+	flag.DurationVar(&gitPollInterval, "git-poll-interval", 5*time.Minute,
+		"How often the branch or tag of each Release with a git source is checked for new commits.")
+	flag.StringVar(&indexURL, "index-url", "https://bosh.io",
+		"The bosh.io-compatible index, e.g. a local mirror, used by Releases and BaseImages tracking the latest version or a version constraint, unless they name one.")
+	flag.DurationVar(&indexPollInterval, "index-poll-interval", time.Hour,
+		"How often Releases and BaseImages tracking the latest version or a version constraint are checked for new versions.")
 	flag.Parse()
 	boshSystemNamespace := os.Getenv("BOSH_SYSTEM_NAMESPACE")
 
 	ctrl.SetLogger(zap.Logger(true))
 
+	remoteclients.SetArtifactsDir(artifactsDir)
+	remoteclients.SetDefaultIndexURL(indexURL)
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -66,10 +93,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientCache := remoteclients.NewClientCache(clientCacheIdleTimeout, directorMaxConcurrentOperations)
+
 	err = (&controllers.ReleaseReconciler{
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Release"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+		GitPollInterval:     gitPollInterval,
+		IndexPollInterval:   indexPollInterval,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Release")
@@ -79,6 +111,8 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("BaseImage"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+		IndexPollInterval:   indexPollInterval,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BaseImage")
@@ -88,6 +122,7 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Team"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Team")
@@ -97,6 +132,7 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Extension"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Extension")
@@ -106,6 +142,7 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("AZ"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AZ")
@@ -115,6 +152,7 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Network"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Network")
@@ -124,6 +162,8 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Director"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+		HealthCheckInterval: directorHealthCheckInterval,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Director")
@@ -133,22 +173,89 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Compilation"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Compilation")
 		os.Exit(1)
 	}
+	err = (&controllers.RoleReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("Role"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Role")
+		os.Exit(1)
+	}
 	err = (&controllers.DeploymentReconciler{
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Deployment"),
 		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Deployment")
 		os.Exit(1)
 	}
+	err = (&controllers.CompiledReleaseReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("CompiledRelease"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CompiledRelease")
+		os.Exit(1)
+	}
+	err = (&controllers.AddonReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("Addon"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Addon")
+		os.Exit(1)
+	}
+	err = (&controllers.CPIReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("CPI"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CPI")
+		os.Exit(1)
+	}
+	err = (&controllers.VMTypeReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("VMType"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VMType")
+		os.Exit(1)
+	}
+	err = (&controllers.DiskTypeReconciler{
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("DiskType"),
+		BOSHSystemNamespace: boshSystemNamespace,
+		ClientCache:         clientCache,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DiskType")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	metrics.Registry.MustRegister(controllers.NewAvailabilityCollector(
+		mgr.GetClient(),
+		ctrl.Log.WithName("metrics"),
+	))
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
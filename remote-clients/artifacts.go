@@ -0,0 +1,546 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// apiClient makes small requests, such as to a version index or for a
+	// registry token, which should complete quickly.
+	apiClient = &http.Client{Timeout: time.Minute}
+
+	// downloadClient fetches tarballs, which may be several gigabytes, so it
+	// allows much longer for the body, but not for the server to respond.
+	downloadClient = &http.Client{
+		Timeout: time.Hour,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           directDialer.DialContext,
+			TLSHandshakeTimeout:   30 * time.Second,
+			ResponseHeaderTimeout: time.Minute,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+)
+
+// ArtifactSource is where the controller fetches a release or stemcell
+// tarball from itself, to upload it as a file to a Director which can't
+// reach the tarball's URL. Exactly one of its fields is set.
+type ArtifactSource struct {
+	// File is a tarball the controller has already built or fetched
+	File string
+
+	// PVC is a claim name and a path within it; the claim is expected to be
+	// mounted into the controller under the artifacts directory, in a
+	// directory for the namespace of the resource using it
+	PVC *PVCArtifact
+
+	// URL is an HTTP(S) URL reachable from the controller
+	URL string
+
+	// OCI is an image reference whose first layer is the tarball
+	OCI *OCIArtifact
+}
+
+type PVCArtifact struct {
+	Namespace string
+	ClaimName string
+	Path      string
+}
+
+type OCIArtifact struct {
+	Image    string
+	Username string
+	Password string
+}
+
+var artifactsDir = "/var/lib/boshv3/artifacts"
+
+// SetArtifactsDir sets the directory under which PersistentVolumeClaims
+// holding artifacts are mounted, each at <namespace>/<claim name>.
+func SetArtifactsDir(dir string) {
+	artifactsDir = dir
+}
+
+// DigestMismatchError is returned when a fetched tarball doesn't match the
+// SHA1 (or "sha256:" prefixed SHA256) given for it.
+type DigestMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e DigestMismatchError) Error() string {
+	return fmt.Sprintf("expected artifact digest %s but got %s", e.Expected, e.Actual)
+}
+
+// MissingDigestError is returned instead of fetching a tarball from a URL
+// for which no SHA1 is given, since nothing else vouches for its content.
+type MissingDigestError struct {
+	URL string
+}
+
+func (e MissingDigestError) Error() string {
+	return fmt.Sprintf("a sha1 is required to fetch an artifact from %s", e.URL)
+}
+
+// artifactFile is a fetched tarball, as uploaded by bosh-cli; closing a
+// downloaded tarball also removes it.
+type artifactFile struct {
+	*os.File
+	temporary bool
+}
+
+func (f artifactFile) Close() error {
+	err := f.File.Close()
+	if f.temporary {
+		os.Remove(f.File.Name())
+	}
+	return err
+}
+
+// fetchArtifact fetches the tarball from source and verifies it against
+// digest, if given, returning it open and ready to be read from the start. A
+// digest is required for a URL; OCI layers are also verified against their
+// own digest.
+func fetchArtifact(source ArtifactSource, digest string) (artifactFile, error) {
+	var f artifactFile
+	var err error
+
+	switch {
+	case source.File != "":
+		var file *os.File
+		file, err = os.Open(source.File)
+		f = artifactFile{File: file}
+	case source.PVC != nil:
+		f, err = openPVCArtifact(*source.PVC)
+	case source.URL != "":
+		if digest == "" {
+			return artifactFile{}, MissingDigestError{URL: source.URL}
+		}
+		f, err = downloadArtifact(source.URL, nil)
+	case source.OCI != nil:
+		f, err = pullOCIArtifact(*source.OCI)
+	default:
+		err = fmt.Errorf("artifact source has no PVC, URL or OCI image")
+	}
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	if digest == "" {
+		return f, nil
+	}
+
+	if err := verifyDigest(f.File, digest); err != nil {
+		f.Close()
+		return artifactFile{}, err
+	}
+
+	return f, nil
+}
+
+// pvcArtifactPath finds a path within a claim, which is only ever looked for
+// in the directory for the namespace of the resource using it, so that one
+// tenant can't read or write another tenant's claims.
+func pvcArtifactPath(pvc PVCArtifact) (string, error) {
+	if !isPathElement(pvc.Namespace) {
+		return "", fmt.Errorf("invalid namespace %q", pvc.Namespace)
+	}
+
+	if !isPathElement(pvc.ClaimName) {
+		return "", fmt.Errorf("invalid PersistentVolumeClaim name %q", pvc.ClaimName)
+	}
+
+	// Cleaning the path as if it were absolute keeps it within the claim.
+	return resolveWithin(
+		filepath.Join(artifactsDir, pvc.Namespace, pvc.ClaimName),
+		filepath.Clean("/"+pvc.Path),
+	)
+}
+
+// resolveWithin resolves any symlinks in the part of a path within root which
+// already exists, refusing one which leads out of root, so that a symlink
+// left in a claim can't be followed to another tenant's claim. The rest of
+// the path, which is yet to be created, can't contain any symlinks, except a
+// dangling one, which is refused too.
+func resolveWithin(root, path string) (string, error) {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if os.IsNotExist(err) {
+		// Nothing has been written to the claim yet.
+		return filepath.Join(root, path), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	existing, rest := filepath.Join(resolvedRoot, path), ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			existing = resolved
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if _, err := os.Lstat(existing); err == nil {
+			return "", fmt.Errorf("%s contains a dangling symlink", path)
+		}
+
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = filepath.Dir(existing)
+	}
+
+	if existing != resolvedRoot && !strings.HasPrefix(existing, resolvedRoot+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s leads outside its PersistentVolumeClaim", path)
+	}
+
+	return filepath.Join(existing, rest), nil
+}
+
+func isPathElement(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsRune(name, filepath.Separator)
+}
+
+func openPVCArtifact(pvc PVCArtifact) (artifactFile, error) {
+	path, err := pvcArtifactPath(pvc)
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	return artifactFile{File: f}, nil
+}
+
+// writePVCArtifact writes a tarball to a PersistentVolumeClaim, replacing
+// any tarball already at its path only once the new one is complete.
+func writePVCArtifact(pvc PVCArtifact, write func(io.Writer) error) error {
+	path, err := pvcArtifactPath(pvc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".boshv3-artifact-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// PVCArtifactExists is true if there is a tarball with the given SHA1 at the
+// PersistentVolumeClaim path, and false if there is no tarball there. Any
+// other tarball there, or any tarball at all if no SHA1 is given, was not
+// written by the caller, and is an ArtifactConflictError.
+func PVCArtifactExists(pvc PVCArtifact, sha1 string) (bool, error) {
+	path, err := pvcArtifactPath(pvc)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if sha1 == "" {
+		return false, ArtifactConflictError{Path: pvc.Path}
+	}
+
+	if err := verifyDigest(f, sha1); err != nil {
+		if _, ok := err.(DigestMismatchError); ok {
+			return false, ArtifactConflictError{Path: pvc.Path}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RemovePVCArtifact removes the tarball at the PersistentVolumeClaim path if
+// it has the given SHA1, leaving any other tarball there alone.
+func RemovePVCArtifact(pvc PVCArtifact, sha1 string) error {
+	exists, err := PVCArtifactExists(pvc, sha1)
+	if _, ok := err.(ArtifactConflictError); ok || !exists {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	path, err := pvcArtifactPath(pvc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// ArtifactConflictError is returned instead of writing a tarball to a
+// PersistentVolumeClaim path which already holds a different one.
+type ArtifactConflictError struct {
+	Path string
+}
+
+func (e ArtifactConflictError) Error() string {
+	return fmt.Sprintf("%s already holds a tarball which was not written by this resource", e.Path)
+}
+
+func verifyDigest(f *os.File, digest string) error {
+	var h hash.Hash
+	var prefix string
+
+	if strings.HasPrefix(digest, "sha256:") {
+		h, prefix = sha256.New(), "sha256:"
+	} else {
+		h = sha1.New()
+	}
+
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if actual := prefix + hex.EncodeToString(h.Sum(nil)); actual != digest {
+		return DigestMismatchError{Expected: digest, Actual: actual}
+	}
+
+	return nil
+}
+
+// downloadArtifact downloads url to a temporary file, setting any headers
+// given.
+func downloadArtifact(url string, header http.Header) (artifactFile, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return artifactFile{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return artifactFile{}, HTTPStatusError{URL: url, Code: resp.StatusCode}
+	}
+
+	f, err := ioutil.TempFile("", "boshv3-artifact-")
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	artifact := artifactFile{File: f, temporary: true}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		artifact.Close()
+		return artifactFile{}, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		artifact.Close()
+		return artifactFile{}, err
+	}
+
+	return artifact, nil
+}
+
+var layerDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+var imageReferencePattern = regexp.MustCompile(`^([^/]+)/([^:@]+)(?::([^@]+))?(?:@(.+))?$`)
+
+// pullOCIArtifact fetches the first layer of an OCI image from its registry
+// using the distribution API, authenticating with a bearer token if the
+// registry asks for one.
+func pullOCIArtifact(oci OCIArtifact) (artifactFile, error) {
+	m := imageReferencePattern.FindStringSubmatch(oci.Image)
+	if m == nil {
+		return artifactFile{}, fmt.Errorf("invalid OCI image reference %q", oci.Image)
+	}
+
+	registry, repository, reference := m[1], m[2], m[4]
+	if reference == "" {
+		reference = m[3]
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+
+	base := fmt.Sprintf("https://%s/v2/%s", registry, repository)
+
+	header := http.Header{}
+	header.Set("Accept", strings.Join([]string{
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}, ", "))
+
+	token, err := registryToken(base+"/manifests/"+reference, oci)
+	if err != nil {
+		return artifactFile{}, err
+	}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	} else if oci.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(oci.Username + ":" + oci.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+
+	manifestFile, err := downloadArtifact(base+"/manifests/"+reference, header)
+	if err != nil {
+		return artifactFile{}, err
+	}
+	defer manifestFile.Close()
+
+	var manifest struct {
+		Layers []struct {
+			Digest string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.NewDecoder(manifestFile).Decode(&manifest); err != nil {
+		return artifactFile{}, err
+	}
+
+	if len(manifest.Layers) == 0 {
+		return artifactFile{}, fmt.Errorf("OCI image %s has no layers", oci.Image)
+	}
+
+	// The layer is addressed by its digest, but the registry, or anything in
+	// between, isn't trusted to have returned the content it names.
+	digest := manifest.Layers[0].Digest
+	if !layerDigestPattern.MatchString(digest) {
+		return artifactFile{}, fmt.Errorf("OCI image %s has unsupported layer digest %q", oci.Image, digest)
+	}
+
+	layer, err := downloadArtifact(base+"/blobs/"+digest, header)
+	if err != nil {
+		return artifactFile{}, err
+	}
+
+	if err := verifyDigest(layer.File, digest); err != nil {
+		layer.Close()
+		return artifactFile{}, err
+	}
+
+	return layer, nil
+}
+
+var bearerChallengePattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// registryToken returns a token for pulling from a registry which challenges
+// for one, or "" for a registry which doesn't.
+func registryToken(url string, oci OCIArtifact) (string, error) {
+	resp, err := apiClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(challenge, "Bearer ") {
+		return "", nil
+	}
+
+	params := map[string]string{}
+	for _, m := range bearerChallengePattern.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+
+	req, err := http.NewRequest("GET", params["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+
+	q := req.URL.Query()
+	q.Set("service", params["service"])
+	q.Set("scope", params["scope"])
+	req.URL.RawQuery = q.Encode()
+
+	if oci.Username != "" {
+		req.SetBasicAuth(oci.Username, oci.Password)
+	}
+
+	tokenResp, err := apiClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer tokenResp.Body.Close()
+
+	if tokenResp.StatusCode != http.StatusOK {
+		return "", HTTPStatusError{URL: params["realm"], Code: tokenResp.StatusCode}
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(tokenResp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	return body.AccessToken, nil
+}
@@ -17,7 +17,15 @@ limitations under the License.
 package remoteclients
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
@@ -27,17 +35,33 @@ import (
 )
 
 type BOSHClient interface {
+	Info() (DirectorInfo, error)
+	Locks() ([]Lock, error)
+
 	HasRelease(string, string) (bool, error)
 	UploadRelease(string, string) error
+	UploadReleaseFile(ArtifactSource, string) error
 	DeleteRelease(string, string) error
+	ReleaseMetadata(string, string) (ReleaseMetadata, bool, error)
+	ReleaseJobSpec(string, string, string) (JobSpec, bool, error)
 
 	HasBaseImage(string, string) (bool, error)
 	UploadBaseImage(string, string) error
+	UploadBaseImageFile(ArtifactSource, string) error
 	DeleteBaseImage(string, string) error
+	BaseImageMetadata(string, string) (BaseImageMetadata, bool, error)
+
+	ExportRelease(string, CompiledRelease, PVCArtifact) (string, error)
 
 	CreateVMExtension(string, VMExtension) error
 	DeleteVMExtension(string) error
 
+	CreateVMType(string, VMType) error
+	DeleteVMType(string) error
+
+	CreateDiskType(string, DiskType) error
+	DeleteDiskType(string) error
+
 	CreateAZ(string, AZ) error
 	DeleteAZ(string) error
 
@@ -47,21 +71,40 @@ type BOSHClient interface {
 	CreateCompilation(string, Network, AZ, Compilation) error
 	DeleteCompilation(string) error
 
-	CreateDeployment(string, Deployment) error
+	CreateAddon(string, []Release, Addon) error
+	DeleteAddon(string) error
+
+	CreateCPI(string, CPI) error
+	DeleteCPI(string) error
+
+	StartDeployment(string, Deployment) (StartedTask, error)
 	DeleteDeployment(string) error
+	DeploymentDisks(string) ([]InstanceDisks, error)
+
+	OrphanedDisks() ([]OrphanedDisk, error)
+	DeleteOrphanedDisk(string) error
+
+	TaskState(int) (string, error)
+	CancelTask(int) error
 }
 
 type boshClientImpl struct {
-	api boshdir.Director
+	api            directorClient
+	directorConfig boshdir.FactoryConfig
+	dial           dialFunc
+	taskReporter   boshdir.TaskReporter
+	logger         boshlog.Logger
 }
 
 func NewBOSHClient(
+	director string,
 	url string,
 	caCert string,
 	uaaURL string,
 	uaaClientName string,
 	uaaClientSecret string,
 	uaaCACert string,
+	proxy Proxy,
 ) (BOSHClient, error) {
 	logger := boshlog.NewLogger(boshlog.LevelError)
 
@@ -74,7 +117,12 @@ func NewBOSHClient(
 	uaaConfig.ClientSecret = uaaClientSecret
 	uaaConfig.CACert = uaaCACert
 
-	uaa, err := boshuaa.NewFactory(logger).New(uaaConfig)
+	dial, err := proxy.dialer(director)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenFunc, err := newUAATokenFunc(uaaConfig, dial, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -85,37 +133,169 @@ func NewBOSHClient(
 	}
 
 	directorConfig.CACert = caCert
-	directorConfig.TokenFunc = boshuaa.NewClientTokenSession(uaa).TokenFunc
+	directorConfig.TokenFunc = tokenFunc
 
-	api, err := boshdir.NewFactory(logger).New(
-		directorConfig,
-		boshdir.NewNoopTaskReporter(),
-		boshdir.NewNoopFileReporter(),
-	)
+	taskReporter := newTaskDurationReporter(director)
+
+	api, err := newDirectorClient(directorConfig, dial, taskReporter, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return instrumentedBOSHClient{
+		director: director,
+		client: &boshClientImpl{
+			api:            api,
+			directorConfig: directorConfig,
+			dial:           dial,
+			taskReporter:   taskReporter,
+			logger:         logger,
+		},
+	}, nil
+}
+
+type DirectorInfo struct {
+	Name                   string
+	UUID                   string
+	Version                string
+	CPI                    string
+	UserAuthenticationType string
+	Features               map[string]bool
+}
+
+func (c *boshClientImpl) Info() (DirectorInfo, error) {
+	info, err := c.api.Info()
+	if err != nil {
+		return DirectorInfo{}, err
+	}
+
+	features := make(map[string]bool, len(info.Features))
+	for name, feature := range info.Features {
+		features[name] = feature.Status
+	}
+
+	return DirectorInfo{
+		Name:                   info.Name,
+		UUID:                   info.UUID,
+		Version:                info.Version,
+		CPI:                    info.CPI,
+		UserAuthenticationType: info.Auth.Type,
+		Features:               features,
+	}, nil
+}
+
+type Lock struct {
+	Type      string
+	Resource  []string
+	ExpiresAt time.Time
+	TaskID    string
+}
+
+func (c *boshClientImpl) Locks() ([]Lock, error) {
+	locks, err := c.api.Locks()
 	if err != nil {
 		return nil, err
 	}
 
-	return &boshClientImpl{api: api}, nil
+	result := make([]Lock, len(locks))
+	for i, l := range locks {
+		timeout, err := strconv.ParseFloat(l.Timeout, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing timeout %q of lock on %v: %s", l.Timeout, l.Resource, err)
+		}
+
+		result[i] = Lock{
+			Type:      l.Type,
+			Resource:  l.Resource,
+			ExpiresAt: time.Unix(int64(timeout), 0).UTC(),
+			TaskID:    l.TaskID,
+		}
+	}
+
+	return result, nil
 }
 
 func (c *boshClientImpl) HasRelease(releaseName, version string) (bool, error) {
-	return c.api.HasRelease(releaseName, version, boshdir.OSVersionSlug{})
+	return c.api.HasRelease(releaseName, version)
 }
 
 func (c *boshClientImpl) UploadRelease(url, sha1 string) error {
-	return c.api.UploadReleaseURL(url, sha1, false, false)
+	return uploadError("release", c.api.UploadReleaseURL(url, sha1, false, false))
 }
 
-func (c *boshClientImpl) DeleteRelease(releaseName, version string) error {
-	if release, err := c.api.FindRelease(boshdir.NewReleaseSlug(
-		releaseName,
-		version,
-	)); err != nil {
+func (c *boshClientImpl) UploadReleaseFile(source ArtifactSource, sha1 string) error {
+	f, err := fetchArtifact(source, sha1)
+	if err != nil {
 		return err
-	} else {
-		return release.Delete(false)
 	}
+	defer f.Close()
+
+	return uploadError("release", c.api.UploadReleaseFile(f, false, false))
+}
+
+// DeleteRelease deletes a release, succeeding if the Director doesn't have
+// it, as `bosh delete-release` does.
+func (c *boshClientImpl) DeleteRelease(releaseName, version string) error {
+	if err := c.api.DeleteReleaseOrSeries(releaseName, version, false); err != nil {
+		if found, hasErr := c.api.HasRelease(releaseName, version); found || hasErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseMetadata is what the Director knows of an uploaded release.
+type ReleaseMetadata struct {
+	Jobs     []ReleaseJob
+	Packages []string
+}
+
+// ReleaseJob is a job in an uploaded release, with the blob holding its
+// templates and spec, and the names of the links it consumes and provides.
+type ReleaseJob struct {
+	Name        string
+	Fingerprint string
+	BlobstoreID string
+	SHA1        string
+	Consumes    []string
+	Provides    []string
+}
+
+// ReleaseMetadata reads back a release from the Director, and false if the
+// Director doesn't have it.
+func (c *boshClientImpl) ReleaseMetadata(releaseName, version string) (ReleaseMetadata, bool, error) {
+	if present, err := c.api.HasRelease(releaseName, version); err != nil || !present {
+		return ReleaseMetadata{}, false, err
+	}
+
+	release, err := c.api.Release(releaseName, version)
+	if err != nil {
+		return ReleaseMetadata{}, false, err
+	}
+
+	var metadata ReleaseMetadata
+
+	for _, j := range release.Jobs {
+		job := ReleaseJob{
+			Name:        j.Name,
+			Fingerprint: j.Fingerprint,
+			BlobstoreID: j.BlobstoreID,
+			SHA1:        j.SHA1,
+		}
+		for _, l := range j.LinksConsumed {
+			job.Consumes = append(job.Consumes, l.Name)
+		}
+		for _, l := range j.LinksProvided {
+			job.Provides = append(job.Provides, l.Name)
+		}
+		metadata.Jobs = append(metadata.Jobs, job)
+	}
+
+	for _, p := range release.Packages {
+		metadata.Packages = append(metadata.Packages, p.Name)
+	}
+
+	return metadata, true, nil
 }
 
 func (c *boshClientImpl) HasBaseImage(baseImageName, version string) (bool, error) {
@@ -123,22 +303,102 @@ func (c *boshClientImpl) HasBaseImage(baseImageName, version string) (bool, erro
 }
 
 func (c *boshClientImpl) UploadBaseImage(url, sha1 string) error {
-	return c.api.UploadStemcellURL(url, sha1, false)
+	return uploadError("stemcell", c.api.UploadStemcellURL(url, sha1, false))
 }
 
-func (c *boshClientImpl) DeleteBaseImage(baseImageName, version string) error {
-	if stemcell, err := c.api.FindStemcell(boshdir.NewStemcellSlug(
-		baseImageName,
-		version,
-	)); err != nil {
+func (c *boshClientImpl) UploadBaseImageFile(source ArtifactSource, sha1 string) error {
+	f, err := fetchArtifact(source, sha1)
+	if err != nil {
 		return err
-	} else {
-		return stemcell.Delete(false)
 	}
+	defer f.Close()
+
+	return uploadError("stemcell", c.api.UploadStemcellFile(f, false))
+}
+
+// DeleteBaseImage deletes a stemcell, succeeding if the Director doesn't have
+// it, as `bosh delete-stemcell` does.
+func (c *boshClientImpl) DeleteBaseImage(baseImageName, version string) error {
+	if err := c.api.DeleteStemcell(baseImageName, version, false); err != nil {
+		if found, hasErr := c.api.HasStemcell(baseImageName, version); found || hasErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BaseImageMetadata is what the Director knows of an uploaded stemcell.
+type BaseImageMetadata struct {
+	OS  string
+	CPI string
+	CID string
+}
+
+// BaseImageMetadata reads back a stemcell from the Director, and false if
+// the Director doesn't have it. Stemcells are listed rather than found by
+// slug, since only the listing includes their OS and CPI.
+func (c *boshClientImpl) BaseImageMetadata(baseImageName, version string) (BaseImageMetadata, bool, error) {
+	stemcells, err := c.api.Stemcells()
+	if err != nil {
+		return BaseImageMetadata{}, false, err
+	}
+
+	for _, s := range stemcells {
+		if s.Name == baseImageName && s.Version == version {
+			return BaseImageMetadata{OS: s.OperatingSystem, CPI: s.CPI, CID: s.CID}, true, nil
+		}
+	}
+
+	return BaseImageMetadata{}, false, nil
+}
+
+// CompiledRelease is a release compiled against a stemcell OS and version.
+type CompiledRelease struct {
+	Release   Release
+	OS        string
+	OSVersion string
+}
+
+// ExportRelease exports a release compiled for a deployment, as with
+// `bosh export-release`, and writes the tarball to dest, returning its SHA1.
+func (c *boshClientImpl) ExportRelease(
+	deployment string,
+	release CompiledRelease,
+	dest PVCArtifact,
+) (string, error) {
+	result, err := c.api.ExportRelease(
+		deployment,
+		boshdir.NewReleaseSlug(release.Release.Name, release.Release.Version),
+		boshdir.NewOSVersionSlug(release.OS, release.OSVersion),
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// The tarball only replaces whatever is at dest once it matches the SHA1
+	// the Director recorded for it.
+	if err := writePVCArtifact(dest, func(w io.Writer) error {
+		h := sha1.New()
+		if err := c.api.DownloadResourceUnchecked(result.BlobstoreID, io.MultiWriter(w, h)); err != nil {
+			return err
+		}
+
+		if actual := hex.EncodeToString(h.Sum(nil)); actual != result.SHA1 {
+			return DigestMismatchError{Expected: result.SHA1, Actual: actual}
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return result.SHA1, nil
 }
 
 type AZ struct {
 	Name            string                `json:"name"`
+	CPI             string                `json:"cpi,omitempty"`
 	CloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
 }
 
@@ -179,19 +439,96 @@ type VMResources struct {
 	EphemeralDiskSize int `json:"ephemeral_disk_size"`
 }
 
+type VMType struct {
+	Name            string                `json:"name"`
+	CloudProperties *runtime.RawExtension `json:"cloud_properties"`
+}
+
+type DiskType struct {
+	Name            string                `json:"name"`
+	DiskSize        int                   `json:"disk_size"`
+	CloudProperties *runtime.RawExtension `json:"cloud_properties,omitempty"`
+}
+
 type cloudConfig struct {
 	AZs          []AZ          `json:"azs,omitempty"`
 	VMExtensions []VMExtension `json:"vm_extensions,omitempty"`
+	VMTypes      []VMType      `json:"vm_types,omitempty"`
+	DiskTypes    []DiskType    `json:"disk_types,omitempty"`
 	Networks     []Network     `json:"networks,omitempty"`
 	Compilation  *Compilation  `json:"compilation,omitempty"`
 }
 
+// cpiConfig is a named cpi-type config holding a single CPI.
+type cpiConfig struct {
+	CPIs []CPI `json:"cpis"`
+}
+
+type CPI struct {
+	Name       string                `json:"name"`
+	Type       string                `json:"type"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// runtimeConfig is a named runtime-type config holding a single addon and
+// the releases its jobs come from.
+type runtimeConfig struct {
+	Releases []Release `json:"releases"`
+	Addons   []Addon   `json:"addons"`
+}
+
+type Addon struct {
+	Name    string          `json:"name"`
+	Jobs    []AddonJob      `json:"jobs"`
+	Include *AddonPlacement `json:"include,omitempty"`
+	Exclude *AddonPlacement `json:"exclude,omitempty"`
+}
+
+type AddonJob struct {
+	Name       string                `json:"name"`
+	Release    string                `json:"release"`
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// AddonPlacement is a placement rule for an addon. An instance group matches
+// it if it matches every kind of criterion given, and any one of the values
+// given for each kind.
+type AddonPlacement struct {
+	Deployments []string         `json:"deployments,omitempty"`
+	Jobs        []AddonPlacedJob `json:"jobs,omitempty"`
+	Teams       []string         `json:"teams,omitempty"`
+}
+
+type AddonPlacedJob struct {
+	Name    string `json:"name"`
+	Release string `json:"release"`
+}
+
 type Deployment struct {
 	Name           string           `json:"name"`
 	Update         DeploymentUpdate `json:"update"`
 	Releases       []Release        `json:"releases"`
 	Stemcells      []Stemcell       `json:"stemcells"`
 	InstanceGroups []InstanceGroup  `json:"instance_groups"`
+	Variables      []Variable       `json:"variables,omitempty"`
+}
+
+// Variable is a credential the Director's config server generates for a
+// deployment, which job properties reference as ((name)).
+type Variable struct {
+	Name    string           `json:"name"`
+	Type    string           `json:"type"`
+	Options *VariableOptions `json:"options,omitempty"`
+}
+
+type VariableOptions struct {
+	CA               string   `json:"ca,omitempty"`
+	IsCA             bool     `json:"is_ca,omitempty"`
+	CommonName       string   `json:"common_name,omitempty"`
+	AlternativeNames []string `json:"alternative_names,omitempty"`
+	ExtendedKeyUsage []string `json:"extended_key_usage,omitempty"`
+	Duration         int      `json:"duration,omitempty"`
+	Length           int      `json:"length,omitempty"`
 }
 
 type DeploymentUpdate struct {
@@ -220,9 +557,11 @@ type InstanceGroup struct {
 	Instances          int                 `json:"instances"`
 	Jobs               []Job               `json:"jobs"`
 	VMExtensions       []string            `json:"vm_extensions"`
-	VMResources        VMResources         `json:"vm_resources"`
+	VMResources        *VMResources        `json:"vm_resources,omitempty"`
+	VMType             string              `json:"vm_type,omitempty"`
 	Stemcell           string              `json:"stemcell"`
-	PersistentDiskSize int                 `json:"persistent_disk_size"`
+	PersistentDiskSize int                 `json:"persistent_disk_size,omitempty"`
+	PersistentDiskType string              `json:"persistent_disk_type,omitempty"`
 	Networks           []DeploymentNetwork `json:"networks"`
 }
 
@@ -236,7 +575,7 @@ type Job struct {
 
 type ConsumesLink struct {
 	From       string `json:"from"`
-	Deployment string `json:"deployment"`
+	Deployment string `json:"deployment,omitempty"`
 }
 
 type ProvidesLink struct {
@@ -261,6 +600,22 @@ func (c *boshClientImpl) DeleteVMExtension(name string) error {
 	return c.deleteCloudConfig(name)
 }
 
+func (c *boshClientImpl) CreateVMType(name string, vmType VMType) error {
+	return c.updateCloudConfig(name, cloudConfig{VMTypes: []VMType{vmType}})
+}
+
+func (c *boshClientImpl) DeleteVMType(name string) error {
+	return c.deleteCloudConfig(name)
+}
+
+func (c *boshClientImpl) CreateDiskType(name string, diskType DiskType) error {
+	return c.updateCloudConfig(name, cloudConfig{DiskTypes: []DiskType{diskType}})
+}
+
+func (c *boshClientImpl) DeleteDiskType(name string) error {
+	return c.deleteCloudConfig(name)
+}
+
 func (c *boshClientImpl) CreateAZ(name string, az AZ) error {
 	return c.updateCloudConfig(name, cloudConfig{AZs: []AZ{az}})
 }
@@ -297,46 +652,283 @@ func (c *boshClientImpl) DeleteCompilation(name string) error {
 	return c.deleteCloudConfig(name)
 }
 
+func (c *boshClientImpl) CreateAddon(name string, releases []Release, addon Addon) error {
+	return c.updateConfig(
+		"runtime",
+		name,
+		runtimeConfig{
+			Releases: releases,
+			Addons:   []Addon{addon},
+		},
+	)
+}
+
+func (c *boshClientImpl) DeleteAddon(name string) error {
+	return c.deleteConfig("runtime", name)
+}
+
+func (c *boshClientImpl) CreateCPI(name string, cpi CPI) error {
+	return c.updateConfig("cpi", name, cpiConfig{CPIs: []CPI{cpi}})
+}
+
+func (c *boshClientImpl) DeleteCPI(name string) error {
+	return c.deleteConfig("cpi", name)
+}
+
 func (c *boshClientImpl) updateCloudConfig(
 	name string,
 	cloudConfig cloudConfig,
 ) error {
-	bytes, err := json.Marshal(cloudConfig)
+	return c.updateConfig("cloud", name, cloudConfig)
+}
+
+func (c *boshClientImpl) updateConfig(
+	configType string,
+	name string,
+	config interface{},
+) error {
+	content, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	diffBody, err := json.Marshal(boshdir.UpdateConfigBody{Type: configType, Name: name, Content: string(content)})
+	if err != nil {
+		return err
+	}
+
+	diff, err := c.api.DiffConfig(diffBody)
 	if err != nil {
 		return err
 	}
 
-	configDiff, err := c.api.DiffConfig("cloud", name, bytes)
+	// The config is only replaced if it hasn't changed since it was diffed,
+	// as with `bosh update-config`.
+	body, err := json.Marshal(boshdir.UpdateConfigBody{
+		Type:             configType,
+		Name:             name,
+		Content:          string(content),
+		ExpectedLatestId: diff.From["id"],
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = c.api.UpdateConfig("cloud", name, configDiff.FromId, bytes)
-	return err
+	if _, _, err := c.api.requests.RawPost("/configs", body, func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+	}); err != nil {
+		return fmt.Errorf("updating %s config %s: %s", configType, name, err)
+	}
+
+	return nil
 }
 
 func (c *boshClientImpl) deleteCloudConfig(name string) error {
-	_, err := c.api.DeleteConfig("cloud", name)
-	return err
+	return c.deleteConfig("cloud", name)
 }
 
-func (c *boshClientImpl) CreateDeployment(name string, deployment Deployment) error {
+// deleteConfig deletes a config, succeeding if the Director doesn't have it.
+func (c *boshClientImpl) deleteConfig(configType, name string) error {
+	query := url.Values{}
+	query.Add("type", configType)
+	query.Add("name", name)
+
+	if _, resp, err := c.api.requests.RawDelete("/configs?" + query.Encode()); err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("deleting %s config %s: %s", configType, name, err)
+	}
+
+	return nil
+}
+
+// StartedTask identifies a BOSH task which is still running after the call
+// which started it has returned. Done is closed once the task has finished.
+type StartedTask struct {
+	ID   int
+	Done <-chan struct{}
+}
+
+// StartDeployment returns as soon as the Director has started the deploy
+// task, rather than waiting for it to finish, so that the task can be
+// tracked, and cancelled, while it runs.
+func (c *boshClientImpl) StartDeployment(name string, deployment Deployment) (StartedTask, error) {
 	bytes, err := json.Marshal(deployment)
 	if err != nil {
-		return err
+		return StartedTask{}, err
 	}
 
-	if d, err := c.api.FindDeployment(name); err != nil {
-		return err
-	} else {
-		return d.Update(bytes, boshdir.UpdateOpts{})
+	started := make(chan int, 1)
+
+	api, err := newDirectorClient(
+		c.directorConfig,
+		c.dial,
+		taskStartedReporter{TaskReporter: c.taskReporter, started: started},
+		c.logger,
+	)
+	if err != nil {
+		return StartedTask{}, err
 	}
+
+	finished := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		finished <- api.UpdateDeployment(bytes, boshdir.UpdateOpts{})
+		close(done)
+	}()
+
+	select {
+	case id := <-started:
+		return StartedTask{ID: id, Done: done}, nil
+	case err := <-finished:
+		select {
+		case id := <-started:
+			return StartedTask{ID: id, Done: done}, nil
+		default:
+		}
+
+		if err == nil {
+			err = fmt.Errorf("deploying %s did not start a task", name)
+		}
+		return StartedTask{}, err
+	}
+}
+
+// taskStartedReporter passes on the ID of the task started by a single call
+type taskStartedReporter struct {
+	boshdir.TaskReporter
+	started chan<- int
 }
 
+func (r taskStartedReporter) TaskStarted(id int) {
+	r.TaskReporter.TaskStarted(id)
+
+	select {
+	case r.started <- id:
+	default:
+	}
+}
+
+// DeleteDeployment deletes a deployment, succeeding if the Director doesn't
+// have it, as `bosh delete-deployment` does.
 func (c *boshClientImpl) DeleteDeployment(name string) error {
-	if d, err := c.api.FindDeployment(name); err != nil {
+	err := c.api.DeleteDeployment(name, false)
+	if err == nil {
+		return nil
+	}
+
+	deployments, listErr := c.api.Deployments()
+	if listErr != nil {
 		return err
+	}
+
+	for _, d := range deployments {
+		if d.Name == name {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InstanceDisks are the CIDs of the persistent disks attached to an instance
+// of a deployment, named as group/id.
+type InstanceDisks struct {
+	Instance string
+	DiskCIDs []string
+}
+
+// DeploymentDisks lists the persistent disks of each instance of a
+// deployment which has any.
+func (c *boshClientImpl) DeploymentDisks(name string) ([]InstanceDisks, error) {
+	infos, err := c.api.DeploymentInstanceInfos(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var disks []InstanceDisks
+	for _, info := range infos {
+		if len(info.DiskIDs) == 0 {
+			continue
+		}
+
+		disks = append(disks, InstanceDisks{
+			Instance: fmt.Sprintf("%s/%s", info.JobName, info.ID),
+			DiskCIDs: info.DiskIDs,
+		})
+	}
+
+	return disks, nil
+}
+
+// OrphanedDisk is a persistent disk the Director has detached and kept,
+// e.g. after its deployment was deleted or its size changed.
+type OrphanedDisk struct {
+	CID        string
+	Size       uint64
+	Deployment string
+	Instance   string
+	AZ         string
+	OrphanedAt time.Time
+}
+
+func (c *boshClientImpl) OrphanedDisks() ([]OrphanedDisk, error) {
+	disks, err := c.api.OrphanDisks()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]OrphanedDisk, len(disks))
+	for i, d := range disks {
+		orphanedAt, err := boshdir.TimeParser{}.Parse(d.OrphanedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing orphaned time %q of disk %s: %s", d.OrphanedAt, d.CID, err)
+		}
+
+		result[i] = OrphanedDisk{
+			CID:        d.CID,
+			Size:       d.Size,
+			Deployment: d.DeploymentName,
+			Instance:   d.InstanceName,
+			AZ:         d.AZ,
+			OrphanedAt: orphanedAt.UTC(),
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteOrphanedDisk deletes an orphaned disk, succeeding if the Director
+// doesn't have it, as `bosh delete-disk` does.
+func (c *boshClientImpl) DeleteOrphanedDisk(cid string) error {
+	err := c.api.DeleteOrphanDisk(cid)
+	if err == nil {
+		return nil
+	}
+
+	disks, listErr := c.api.OrphanDisks()
+	if listErr != nil {
+		return err
+	}
+
+	for _, d := range disks {
+		if d.CID == cid {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *boshClientImpl) TaskState(id int) (string, error) {
+	if t, err := c.api.Task(id); err != nil {
+		return "", err
 	} else {
-		return d.Delete(false)
+		return t.State, nil
 	}
 }
+
+func (c *boshClientImpl) CancelTask(id int) error {
+	return c.api.CancelTask(id)
+}
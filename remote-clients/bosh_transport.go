@@ -0,0 +1,199 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
+	"github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// bosh-cli's Director and UAA factories always build their HTTP clients on
+// bosh-utils' default dialer, which can only be proxied through the
+// process-wide BOSH_ALL_PROXY. Instead, the controller uses bosh-cli's
+// exported Director and UAA clients directly, on HTTP clients it builds with
+// bosh-utils, but dialing through the Director's own proxy, if it has one.
+
+// directorClient is a bosh-cli Director client, together with a request
+// client for the Director's config API, which bosh-cli's client only exposes
+// through its Director.
+type directorClient struct {
+	boshdir.Client
+	requests boshdir.ClientRequest
+}
+
+// newDirectorClient builds a Director client whose connections are made by
+// dial, and which reports the tasks it waits for to taskReporter.
+func newDirectorClient(
+	config boshdir.FactoryConfig,
+	dial dialFunc,
+	taskReporter boshdir.TaskReporter,
+	logger boshlog.Logger,
+) (directorClient, error) {
+	if err := config.Validate(); err != nil {
+		return directorClient{}, fmt.Errorf("validating Director connection config: %s", err)
+	}
+
+	certPool, err := config.CACertPool()
+	if err != nil {
+		return directorClient{}, err
+	}
+
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+
+	rawClient, err := newHTTPClient(certPool, dial)
+	if err != nil {
+		return directorClient{}, err
+	}
+
+	authAdjustment := boshdir.NewAuthRequestAdjustment(
+		config.TokenFunc,
+		config.Client,
+		config.ClientSecret,
+	)
+
+	// The Director redirects to the tasks it starts using its own idea of its
+	// address, which may not be the one the controller reaches it at.
+	rawClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > 10 {
+			return fmt.Errorf("too many redirects")
+		}
+
+		// Redirected requests aren't retried, so their token is refreshed now.
+		if err := authAdjustment.Adjust(req, true); err != nil {
+			return err
+		}
+
+		req.URL.Host = address
+
+		authorization := req.Header.Get("Authorization")
+		req.Header = make(http.Header)
+		if authorization != "" {
+			req.Header.Add("Authorization", authorization)
+		}
+		req.Body = nil
+
+		return nil
+	}
+
+	httpClient := httpclient.NewHTTPClientOpts(
+		boshdir.NewAdjustableClient(
+			httpclient.NewNetworkSafeRetryClient(rawClient, 5, 500*time.Millisecond, logger),
+			authAdjustment,
+		),
+		logger,
+		httpclient.Opts{NoRedactUrlQuery: true},
+	)
+
+	endpoint := (&url.URL{Scheme: "https", Host: address}).String()
+	fileReporter := boshdir.NewNoopFileReporter()
+
+	return directorClient{
+		Client:   boshdir.NewClient(endpoint, httpClient, taskReporter, fileReporter, logger),
+		requests: boshdir.NewClientRequest(endpoint, httpClient, fileReporter, logger),
+	}, nil
+}
+
+// newUAATokenFunc returns a bosh-cli token function which gets client
+// credentials tokens from the UAA, connecting to it with dial. A token is
+// shared by concurrent callers, and only replaced when a request using it has
+// been rejected.
+func newUAATokenFunc(config boshuaa.Config, dial dialFunc, logger boshlog.Logger) (func(bool) (string, error), error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("validating UAA connection config: %s", err)
+	}
+
+	certPool, err := config.CACertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	rawClient, err := newHTTPClient(certPool, dial)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		Path:   config.Path,
+	}
+
+	session := &uaaTokenSession{
+		client: boshuaa.NewClient(
+			endpoint.String(),
+			config.Client,
+			config.ClientSecret,
+			httpclient.NewHTTPClient(
+				httpclient.NewNetworkSafeRetryClient(rawClient, 5, 500*time.Millisecond, logger),
+				logger,
+			),
+			logger,
+		),
+	}
+
+	return session.TokenFunc, nil
+}
+
+type uaaTokenSession struct {
+	client boshuaa.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+func (s *uaaTokenSession) TokenFunc(retried bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" || retried {
+		resp, err := s.client.ClientCredentialsGrant()
+		if err != nil {
+			return "", err
+		}
+
+		s.token = resp.Type + " " + resp.AccessToken
+	}
+
+	return s.token, nil
+}
+
+// newHTTPClient builds bosh-utils' default HTTP client, but dialing with dial
+// rather than bosh-utils' process-wide dialer and proxy settings.
+func newHTTPClient(certPool *x509.CertPool, dial dialFunc) (*http.Client, error) {
+	client := httpclient.CreateDefaultClient(certPool)
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unsupported bosh-utils version: default client's transport is %T", client.Transport)
+	}
+
+	transport.Proxy = nil
+	transport.Dial = dial
+
+	return client, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientCache hands out BOSH and UAA clients, reusing a previously constructed
+// client (and therefore its UAA token) for as long as the Director's URL, the
+// UAA client name and a fingerprint of the remaining connection settings,
+// including the client secret, stay the same. A client whose settings have
+// changed is replaced, and clients that go unused for longer than the idle
+// timeout are evicted.
+//
+// BOSH clients handed out by the cache also share a per-Director limit on
+// concurrent uploads and deploys, given for each Director or else defaulting
+// to the cache's maxConcurrentOperations; zero means no limit.
+type ClientCache struct {
+	idleTimeout             time.Duration
+	maxConcurrentOperations int
+	limiter                 *operationLimiter
+
+	mu          sync.Mutex
+	boshClients map[clientKey]*cachedBOSHClient
+	uaaClients  map[clientKey]*cachedUAAClient
+}
+
+type clientKey struct {
+	url        string
+	clientName string
+}
+
+type cachedBOSHClient struct {
+	fingerprint string
+	lastUsed    time.Time
+	client      BOSHClient
+}
+
+type cachedUAAClient struct {
+	fingerprint string
+	lastUsed    time.Time
+	client      UAAClient
+}
+
+func NewClientCache(idleTimeout time.Duration, maxConcurrentOperations int) *ClientCache {
+	return &ClientCache{
+		idleTimeout:             idleTimeout,
+		maxConcurrentOperations: maxConcurrentOperations,
+		limiter:                 &operationLimiter{inFlight: make(map[string]int)},
+		boshClients:             make(map[clientKey]*cachedBOSHClient),
+		uaaClients:              make(map[clientKey]*cachedUAAClient),
+	}
+}
+
+func (c *ClientCache) BOSHClient(
+	director string,
+	url string,
+	caCert string,
+	uaaURL string,
+	uaaClientName string,
+	uaaClientSecret string,
+	uaaCACert string,
+	proxy Proxy,
+	maxConcurrentOperations int,
+) (BOSHClient, error) {
+	key := clientKey{url: url, clientName: uaaClientName}
+	fp := fingerprint(director, caCert, uaaURL, uaaClientSecret, uaaCACert, proxy.URL, proxy.PrivateKey, proxy.KnownHosts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evictIdle(now)
+
+	if maxConcurrentOperations == 0 {
+		maxConcurrentOperations = c.maxConcurrentOperations
+	}
+
+	if cached, ok := c.boshClients[key]; ok && cached.fingerprint == fp {
+		cached.lastUsed = now
+		return c.limit(director, maxConcurrentOperations, cached.client), nil
+	}
+
+	client, err := NewBOSHClient(director, url, caCert, uaaURL, uaaClientName, uaaClientSecret, uaaCACert, proxy)
+	if err != nil {
+		delete(c.boshClients, key)
+		return nil, err
+	}
+
+	c.boshClients[key] = &cachedBOSHClient{fingerprint: fp, lastUsed: now, client: client}
+	return c.limit(director, maxConcurrentOperations, client), nil
+}
+
+func (c *ClientCache) limit(director string, maxConcurrentOperations int, client BOSHClient) BOSHClient {
+	return limitedBOSHClient{
+		BOSHClient: client,
+		director:   director,
+		limit:      maxConcurrentOperations,
+		limiter:    c.limiter,
+	}
+}
+
+func (c *ClientCache) UAAClient(
+	director string,
+	url string,
+	clientName string,
+	clientSecret string,
+	caCert string,
+	proxy Proxy,
+) (UAAClient, error) {
+	key := clientKey{url: url, clientName: clientName}
+	fp := fingerprint(director, clientSecret, caCert, proxy.URL, proxy.PrivateKey, proxy.KnownHosts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evictIdle(now)
+
+	if cached, ok := c.uaaClients[key]; ok && cached.fingerprint == fp {
+		cached.lastUsed = now
+		return cached.client, nil
+	}
+
+	client, err := NewUAAClient(director, url, clientName, clientSecret, caCert, proxy)
+	if err != nil {
+		delete(c.uaaClients, key)
+		return nil, err
+	}
+
+	c.uaaClients[key] = &cachedUAAClient{fingerprint: fp, lastUsed: now, client: client}
+	return client, nil
+}
+
+func (c *ClientCache) evictIdle(now time.Time) {
+	if c.idleTimeout <= 0 {
+		return
+	}
+
+	for key, cached := range c.boshClients {
+		if now.Sub(cached.lastUsed) > c.idleTimeout {
+			delete(c.boshClients, key)
+		}
+	}
+
+	for key, cached := range c.uaaClients {
+		if now.Sub(cached.lastUsed) > c.idleTimeout {
+			delete(c.uaaClients, key)
+		}
+	}
+}
+
+func fingerprint(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DirectorBusyError is returned instead of starting an upload or deploy when
+// a Director is already running as many of them as it is allowed to.
+type DirectorBusyError struct {
+	Director string
+	Limit    int
+}
+
+func (e DirectorBusyError) Error() string {
+	return fmt.Sprintf(
+		"director %s is already running the maximum of %d concurrent uploads and deploys",
+		e.Director,
+		e.Limit,
+	)
+}
+
+func IsDirectorBusy(err error) bool {
+	_, ok := err.(DirectorBusyError)
+	return ok
+}
+
+// operationLimiter is a per-Director semaphore shared by every BOSH client
+// for that Director, regardless of team.
+type operationLimiter struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func (l *operationLimiter) acquire(director string, limit int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit > 0 && l.inFlight[director] >= limit {
+		return DirectorBusyError{Director: director, Limit: limit}
+	}
+
+	l.inFlight[director]++
+	return nil
+}
+
+func (l *operationLimiter) release(director string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[director]--; l.inFlight[director] <= 0 {
+		delete(l.inFlight, director)
+	}
+}
+
+// limitedBOSHClient fails fast with a DirectorBusyError rather than starting
+// an upload or deploy when the Director is at its limit; all other calls are
+// passed straight through.
+type limitedBOSHClient struct {
+	BOSHClient
+	director string
+	limit    int
+	limiter  *operationLimiter
+}
+
+func (c limitedBOSHClient) UploadRelease(url, sha1 string) error {
+	if err := c.limiter.acquire(c.director, c.limit); err != nil {
+		return err
+	}
+	defer c.limiter.release(c.director)
+
+	return c.BOSHClient.UploadRelease(url, sha1)
+}
+
+func (c limitedBOSHClient) UploadBaseImage(url, sha1 string) error {
+	if err := c.limiter.acquire(c.director, c.limit); err != nil {
+		return err
+	}
+	defer c.limiter.release(c.director)
+
+	return c.BOSHClient.UploadBaseImage(url, sha1)
+}
+
+func (c limitedBOSHClient) UploadReleaseFile(source ArtifactSource, sha1 string) error {
+	if err := c.limiter.acquire(c.director, c.limit); err != nil {
+		return err
+	}
+	defer c.limiter.release(c.director)
+
+	return c.BOSHClient.UploadReleaseFile(source, sha1)
+}
+
+func (c limitedBOSHClient) UploadBaseImageFile(source ArtifactSource, sha1 string) error {
+	if err := c.limiter.acquire(c.director, c.limit); err != nil {
+		return err
+	}
+	defer c.limiter.release(c.director)
+
+	return c.BOSHClient.UploadBaseImageFile(source, sha1)
+}
+
+// StartDeployment holds its place in the Director's limit until the deploy
+// task has finished, not just until it has started.
+func (c limitedBOSHClient) StartDeployment(name string, deployment Deployment) (StartedTask, error) {
+	if err := c.limiter.acquire(c.director, c.limit); err != nil {
+		return StartedTask{}, err
+	}
+
+	task, err := c.BOSHClient.StartDeployment(name, deployment)
+	if err != nil {
+		c.limiter.release(c.director)
+		return StartedTask{}, err
+	}
+
+	go func() {
+		<-task.Done
+		c.limiter.release(c.director)
+	}()
+
+	return task, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+// ErrorClass describes whether retrying a failed BOSH or UAA call could
+// succeed without anything about the request changing.
+type ErrorClass string
+
+const (
+	// TransientErrorClass errors, e.g. an unreachable Director, a 5xx
+	// response, or a deploy task which failed, perhaps because of an IaaS
+	// quota or timeout, may succeed if retried.
+	TransientErrorClass ErrorClass = "Transient"
+	// AuthErrorClass errors are 401 and 403 responses, which may succeed once
+	// credentials or permissions have been fixed.
+	AuthErrorClass ErrorClass = "Auth"
+	// PermanentErrorClass errors, e.g. a 404 artifact URL or a SHA1 mismatch,
+	// whether found by the controller when fetching an artifact itself or by
+	// the Director's upload task, will fail again until the request changes.
+	PermanentErrorClass ErrorClass = "Permanent"
+)
+
+// bosh-cli's Director client reports unsuccessful responses only as
+// "... responded with non-successful status code '<code>' ...", and
+// golang.org/x/oauth2, used by the UAA client, as
+// "oauth2: cannot fetch token: <code> ...".
+var libraryStatusCodePattern = regexp.MustCompile(`status code '(\d{3})'|cannot fetch token: (\d{3})`)
+
+// bosh-cli reports a task it waited for which didn't succeed only as
+// "Expected task '<id>' to succeed but state is '<state>'".
+var libraryTaskFailedPattern = regexp.MustCompile(`Expected task '(\d+)' to succeed but state is '(\w+)'`)
+
+// ClassifyError classifies an error returned by a BOSHClient or UAAClient.
+// Errors which can't be recognised are assumed to be transient.
+func ClassifyError(err error) ErrorClass {
+	switch e := err.(type) {
+	case net.Error:
+		return TransientErrorClass
+	case ArtifactConflictError, DigestMismatchError, InvalidGitURLError, InvalidVersionConstraintError,
+		MissingDigestError, UploadFailedError, UploadMismatchError:
+		return PermanentErrorClass
+	case HTTPStatusError:
+		return classifyStatusCode(e.Code)
+	case TaskFailedError:
+		return TransientErrorClass
+	}
+
+	if m := libraryStatusCodePattern.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1] + m[2])
+		return classifyStatusCode(code)
+	}
+
+	return TransientErrorClass
+}
+
+func classifyStatusCode(code int) ErrorClass {
+	switch {
+	case code == 401 || code == 403:
+		return AuthErrorClass
+	case code == 408 || code == 409 || code == 429 || code >= 500:
+		return TransientErrorClass
+	case code >= 400:
+		return PermanentErrorClass
+	}
+
+	return TransientErrorClass
+}
+
+// HTTPStatusError is returned when a server the controller fetches from
+// itself, such as an artifact host, registry or release index, responds with
+// an unsuccessful status code.
+type HTTPStatusError struct {
+	URL  string
+	Code int
+}
+
+func (e HTTPStatusError) Error() string {
+	return fmt.Sprintf("fetching %s responded with status code %d", e.URL, e.Code)
+}
+
+// TaskFailedError is returned when a BOSH task started for a resource, such as
+// a deploy, finished without succeeding.
+type TaskFailedError struct {
+	ID    int
+	State string
+}
+
+func (e TaskFailedError) Error() string {
+	return fmt.Sprintf("task %d finished in state %s", e.ID, e.State)
+}
+
+// UploadFailedError is returned when the Director's task uploading a release
+// or stemcell errored, e.g. because its URL returned a 404 or its SHA1 didn't
+// match, which retrying the same upload won't fix.
+type UploadFailedError struct {
+	Kind   string
+	TaskID int
+}
+
+func (e UploadFailedError) Error() string {
+	return fmt.Sprintf("task %d uploading %s finished in state error", e.TaskID, e.Kind)
+}
+
+// uploadError returns an UploadFailedError in place of bosh-cli's error for an
+// upload task which errored, and any other error as it is. Upload tasks which
+// were cancelled or timed out are left to be retried.
+func uploadError(kind string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	m := libraryTaskFailedPattern.FindStringSubmatch(err.Error())
+	if m == nil || m[2] != "error" {
+		return err
+	}
+
+	id, _ := strconv.Atoi(m[1])
+	return UploadFailedError{Kind: kind, TaskID: id}
+}
+
+// DeploymentLockedError is returned instead of deploying when another BOSH
+// task, such as a manual deploy or a stuck task, holds the deployment's lock.
+type DeploymentLockedError struct {
+	Deployment string
+	TaskID     string
+}
+
+func (e DeploymentLockedError) Error() string {
+	return fmt.Sprintf("deployment %s is locked by task %s", e.Deployment, e.TaskID)
+}
+
+func IsDeploymentLocked(err error) bool {
+	_, ok := err.(DeploymentLockedError)
+	return ok
+}
+
+// TaskRunningError is returned while a BOSH task started for a resource, such
+// as a deploy, is still running.
+type TaskRunningError struct {
+	TaskID int
+}
+
+func (e TaskRunningError) Error() string {
+	return fmt.Sprintf("task %d is still running", e.TaskID)
+}
+
+func IsTaskRunning(err error) bool {
+	_, ok := err.(TaskRunningError)
+	return ok
+}
+
+// UploadMismatchError is returned when the Director doesn't have the release
+// or stemcell an uploaded tarball was declared to be, e.g. because its URL
+// serves a different release.
+type UploadMismatchError struct {
+	Kind    string
+	Name    string
+	Version string
+}
+
+func (e UploadMismatchError) Error() string {
+	return fmt.Sprintf(
+		"uploaded tarball is not %s %s/%s, check that its name and version match the tarball's",
+		e.Kind,
+		e.Name,
+		e.Version,
+	)
+}
+
+// InvalidGitURLError is returned for a git repository URL the controller
+// won't clone from.
+type InvalidGitURLError struct {
+	URL    string
+	Reason string
+}
+
+func (e InvalidGitURLError) Error() string {
+	return fmt.Sprintf("invalid git repository URL %q: %s", e.URL, e.Reason)
+}
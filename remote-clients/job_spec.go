@@ -0,0 +1,144 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// JobSpec is the spec of a job in an uploaded release, as declared in the
+// job.MF file of the job's blob.
+type JobSpec struct {
+	Name       string
+	Properties map[string]JobProperty
+}
+
+// JobProperty is a property declared in a job spec. Properties without a
+// default must be given a value.
+type JobProperty struct {
+	Description string
+	HasDefault  bool
+}
+
+// jobSpecs caches job specs by the SHA1 of their blob, which is immutable.
+var jobSpecs = struct {
+	sync.Mutex
+	bySHA1 map[string]JobSpec
+}{bySHA1: make(map[string]JobSpec)}
+
+// ReleaseJobSpec downloads and parses the spec of a job in an uploaded
+// release, and false if the release has no such job.
+func (c *boshClientImpl) ReleaseJobSpec(releaseName, version, jobName string) (JobSpec, bool, error) {
+	metadata, found, err := c.ReleaseMetadata(releaseName, version)
+	if err != nil {
+		return JobSpec{}, false, err
+	} else if !found {
+		return JobSpec{}, false, fmt.Errorf("release %s/%s not found", releaseName, version)
+	}
+
+	for _, job := range metadata.Jobs {
+		if job.Name != jobName {
+			continue
+		}
+
+		jobSpecs.Lock()
+		spec, cached := jobSpecs.bySHA1[job.SHA1]
+		jobSpecs.Unlock()
+
+		if cached {
+			return spec, true, nil
+		}
+
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(c.api.DownloadResourceUnchecked(job.BlobstoreID, w))
+		}()
+
+		spec, err := parseJobSpec(r)
+		r.Close()
+		if err != nil {
+			return JobSpec{}, false, fmt.Errorf("reading spec of job %s in release %s/%s: %s", jobName, releaseName, version, err)
+		}
+
+		jobSpecs.Lock()
+		jobSpecs.bySHA1[job.SHA1] = spec
+		jobSpecs.Unlock()
+
+		return spec, true, nil
+	}
+
+	return JobSpec{}, false, nil
+}
+
+// parseJobSpec finds and parses the job.MF file in a job's gzipped tarball.
+func parseJobSpec(blob io.Reader) (JobSpec, error) {
+	gz, err := gzip.NewReader(blob)
+	if err != nil {
+		return JobSpec{}, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return JobSpec{}, fmt.Errorf("job.MF not found")
+		} else if err != nil {
+			return JobSpec{}, err
+		}
+
+		if path.Clean(header.Name) != "job.MF" {
+			continue
+		}
+
+		manifest, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return JobSpec{}, err
+		}
+
+		var parsed struct {
+			Name       string                            `json:"name"`
+			Properties map[string]map[string]interface{} `json:"properties"`
+		}
+		if err := yaml.Unmarshal(manifest, &parsed); err != nil {
+			return JobSpec{}, err
+		}
+
+		spec := JobSpec{
+			Name:       parsed.Name,
+			Properties: make(map[string]JobProperty, len(parsed.Properties)),
+		}
+		for name, p := range parsed.Properties {
+			description, _ := p["description"].(string)
+			_, hasDefault := p["default"]
+			spec.Properties[name] = JobProperty{
+				Description: description,
+				HasDefault:  hasDefault,
+			}
+		}
+
+		return spec, nil
+	}
+}
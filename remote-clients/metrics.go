@@ -0,0 +1,345 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "boshv3_remote_request_duration_seconds",
+			Help:    "Duration of BOSH and UAA API calls, by director and operation.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 16),
+		},
+		[]string{"client", "director", "operation"},
+	)
+
+	requestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "boshv3_remote_request_errors_total",
+			Help: "Number of failed BOSH and UAA API calls, by director and operation.",
+		},
+		[]string{"client", "director", "operation"},
+	)
+
+	taskDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "boshv3_bosh_task_duration_seconds",
+			Help:    "Duration of BOSH tasks, from being started until finishing, by director and final state.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		},
+		[]string{"director", "state"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestDuration, requestErrors, taskDuration)
+}
+
+func observeRequest(client, director, operation string, start time.Time, err *error) {
+	requestDuration.WithLabelValues(client, director, operation).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		requestErrors.WithLabelValues(client, director, operation).Inc()
+	}
+}
+
+// taskDurationReporter records how long each BOSH task run by a Director
+// takes, and otherwise discards task output.
+type taskDurationReporter struct {
+	director string
+
+	mu      sync.Mutex
+	started map[int]time.Time
+}
+
+func newTaskDurationReporter(director string) *taskDurationReporter {
+	return &taskDurationReporter{
+		director: director,
+		started:  make(map[int]time.Time),
+	}
+}
+
+func (r *taskDurationReporter) TaskStarted(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started[id] = time.Now()
+}
+
+func (r *taskDurationReporter) TaskFinished(id int, state string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if start, ok := r.started[id]; ok {
+		taskDuration.WithLabelValues(r.director, state).Observe(time.Since(start).Seconds())
+		delete(r.started, id)
+	}
+}
+
+func (r *taskDurationReporter) TaskOutputChunk(int, []byte) {}
+
+type instrumentedBOSHClient struct {
+	director string
+	client   BOSHClient
+}
+
+func (c instrumentedBOSHClient) observe(operation string, start time.Time, err *error) {
+	observeRequest("bosh", c.director, operation, start, err)
+}
+
+func (c instrumentedBOSHClient) Info() (_ DirectorInfo, err error) {
+	defer c.observe("Info", time.Now(), &err)
+	return c.client.Info()
+}
+
+func (c instrumentedBOSHClient) Locks() (_ []Lock, err error) {
+	defer c.observe("Locks", time.Now(), &err)
+	return c.client.Locks()
+}
+
+func (c instrumentedBOSHClient) HasRelease(releaseName, version string) (_ bool, err error) {
+	defer c.observe("HasRelease", time.Now(), &err)
+	return c.client.HasRelease(releaseName, version)
+}
+
+func (c instrumentedBOSHClient) UploadRelease(url, sha1 string) (err error) {
+	defer c.observe("UploadRelease", time.Now(), &err)
+	return c.client.UploadRelease(url, sha1)
+}
+
+func (c instrumentedBOSHClient) UploadReleaseFile(source ArtifactSource, sha1 string) (err error) {
+	defer c.observe("UploadReleaseFile", time.Now(), &err)
+	return c.client.UploadReleaseFile(source, sha1)
+}
+
+func (c instrumentedBOSHClient) DeleteRelease(releaseName, version string) (err error) {
+	defer c.observe("DeleteRelease", time.Now(), &err)
+	return c.client.DeleteRelease(releaseName, version)
+}
+
+func (c instrumentedBOSHClient) ReleaseMetadata(
+	releaseName string,
+	version string,
+) (_ ReleaseMetadata, _ bool, err error) {
+	defer c.observe("ReleaseMetadata", time.Now(), &err)
+	return c.client.ReleaseMetadata(releaseName, version)
+}
+
+func (c instrumentedBOSHClient) ReleaseJobSpec(
+	releaseName string,
+	version string,
+	jobName string,
+) (_ JobSpec, _ bool, err error) {
+	defer c.observe("ReleaseJobSpec", time.Now(), &err)
+	return c.client.ReleaseJobSpec(releaseName, version, jobName)
+}
+
+func (c instrumentedBOSHClient) HasBaseImage(baseImageName, version string) (_ bool, err error) {
+	defer c.observe("HasBaseImage", time.Now(), &err)
+	return c.client.HasBaseImage(baseImageName, version)
+}
+
+func (c instrumentedBOSHClient) UploadBaseImage(url, sha1 string) (err error) {
+	defer c.observe("UploadBaseImage", time.Now(), &err)
+	return c.client.UploadBaseImage(url, sha1)
+}
+
+func (c instrumentedBOSHClient) UploadBaseImageFile(source ArtifactSource, sha1 string) (err error) {
+	defer c.observe("UploadBaseImageFile", time.Now(), &err)
+	return c.client.UploadBaseImageFile(source, sha1)
+}
+
+func (c instrumentedBOSHClient) DeleteBaseImage(baseImageName, version string) (err error) {
+	defer c.observe("DeleteBaseImage", time.Now(), &err)
+	return c.client.DeleteBaseImage(baseImageName, version)
+}
+
+func (c instrumentedBOSHClient) BaseImageMetadata(
+	baseImageName string,
+	version string,
+) (_ BaseImageMetadata, _ bool, err error) {
+	defer c.observe("BaseImageMetadata", time.Now(), &err)
+	return c.client.BaseImageMetadata(baseImageName, version)
+}
+
+func (c instrumentedBOSHClient) ExportRelease(
+	deployment string,
+	release CompiledRelease,
+	dest PVCArtifact,
+) (_ string, err error) {
+	defer c.observe("ExportRelease", time.Now(), &err)
+	return c.client.ExportRelease(deployment, release, dest)
+}
+
+func (c instrumentedBOSHClient) CreateVMExtension(name string, vmExtension VMExtension) (err error) {
+	defer c.observe("CreateVMExtension", time.Now(), &err)
+	return c.client.CreateVMExtension(name, vmExtension)
+}
+
+func (c instrumentedBOSHClient) DeleteVMExtension(name string) (err error) {
+	defer c.observe("DeleteVMExtension", time.Now(), &err)
+	return c.client.DeleteVMExtension(name)
+}
+
+func (c instrumentedBOSHClient) CreateVMType(name string, vmType VMType) (err error) {
+	defer c.observe("CreateVMType", time.Now(), &err)
+	return c.client.CreateVMType(name, vmType)
+}
+
+func (c instrumentedBOSHClient) DeleteVMType(name string) (err error) {
+	defer c.observe("DeleteVMType", time.Now(), &err)
+	return c.client.DeleteVMType(name)
+}
+
+func (c instrumentedBOSHClient) CreateDiskType(name string, diskType DiskType) (err error) {
+	defer c.observe("CreateDiskType", time.Now(), &err)
+	return c.client.CreateDiskType(name, diskType)
+}
+
+func (c instrumentedBOSHClient) DeleteDiskType(name string) (err error) {
+	defer c.observe("DeleteDiskType", time.Now(), &err)
+	return c.client.DeleteDiskType(name)
+}
+
+func (c instrumentedBOSHClient) CreateAZ(name string, az AZ) (err error) {
+	defer c.observe("CreateAZ", time.Now(), &err)
+	return c.client.CreateAZ(name, az)
+}
+
+func (c instrumentedBOSHClient) DeleteAZ(name string) (err error) {
+	defer c.observe("DeleteAZ", time.Now(), &err)
+	return c.client.DeleteAZ(name)
+}
+
+func (c instrumentedBOSHClient) CreateNetwork(name string, network Network) (err error) {
+	defer c.observe("CreateNetwork", time.Now(), &err)
+	return c.client.CreateNetwork(name, network)
+}
+
+func (c instrumentedBOSHClient) DeleteNetwork(name string) (err error) {
+	defer c.observe("DeleteNetwork", time.Now(), &err)
+	return c.client.DeleteNetwork(name)
+}
+
+func (c instrumentedBOSHClient) CreateCompilation(
+	name string,
+	network Network,
+	az AZ,
+	compilation Compilation,
+) (err error) {
+	defer c.observe("CreateCompilation", time.Now(), &err)
+	return c.client.CreateCompilation(name, network, az, compilation)
+}
+
+func (c instrumentedBOSHClient) DeleteCompilation(name string) (err error) {
+	defer c.observe("DeleteCompilation", time.Now(), &err)
+	return c.client.DeleteCompilation(name)
+}
+
+func (c instrumentedBOSHClient) CreateAddon(name string, releases []Release, addon Addon) (err error) {
+	defer c.observe("CreateAddon", time.Now(), &err)
+	return c.client.CreateAddon(name, releases, addon)
+}
+
+func (c instrumentedBOSHClient) DeleteAddon(name string) (err error) {
+	defer c.observe("DeleteAddon", time.Now(), &err)
+	return c.client.DeleteAddon(name)
+}
+
+func (c instrumentedBOSHClient) CreateCPI(name string, cpi CPI) (err error) {
+	defer c.observe("CreateCPI", time.Now(), &err)
+	return c.client.CreateCPI(name, cpi)
+}
+
+func (c instrumentedBOSHClient) DeleteCPI(name string) (err error) {
+	defer c.observe("DeleteCPI", time.Now(), &err)
+	return c.client.DeleteCPI(name)
+}
+
+func (c instrumentedBOSHClient) StartDeployment(name string, deployment Deployment) (_ StartedTask, err error) {
+	defer c.observe("StartDeployment", time.Now(), &err)
+	return c.client.StartDeployment(name, deployment)
+}
+
+func (c instrumentedBOSHClient) DeleteDeployment(name string) (err error) {
+	defer c.observe("DeleteDeployment", time.Now(), &err)
+	return c.client.DeleteDeployment(name)
+}
+
+func (c instrumentedBOSHClient) DeploymentDisks(name string) (_ []InstanceDisks, err error) {
+	defer c.observe("DeploymentDisks", time.Now(), &err)
+	return c.client.DeploymentDisks(name)
+}
+
+func (c instrumentedBOSHClient) OrphanedDisks() (_ []OrphanedDisk, err error) {
+	defer c.observe("OrphanedDisks", time.Now(), &err)
+	return c.client.OrphanedDisks()
+}
+
+func (c instrumentedBOSHClient) DeleteOrphanedDisk(cid string) (err error) {
+	defer c.observe("DeleteOrphanedDisk", time.Now(), &err)
+	return c.client.DeleteOrphanedDisk(cid)
+}
+
+func (c instrumentedBOSHClient) TaskState(id int) (_ string, err error) {
+	defer c.observe("TaskState", time.Now(), &err)
+	return c.client.TaskState(id)
+}
+
+func (c instrumentedBOSHClient) CancelTask(id int) (err error) {
+	defer c.observe("CancelTask", time.Now(), &err)
+	return c.client.CancelTask(id)
+}
+
+type instrumentedUAAClient struct {
+	director string
+	client   UAAClient
+}
+
+func (c instrumentedUAAClient) observe(operation string, start time.Time, err *error) {
+	observeRequest("uaa", c.director, operation, start, err)
+}
+
+func (c instrumentedUAAClient) Authenticate() (err error) {
+	defer c.observe("Authenticate", time.Now(), &err)
+	return c.client.Authenticate()
+}
+
+func (c instrumentedUAAClient) HasClient(name string) (_ bool, err error) {
+	defer c.observe("HasClient", time.Now(), &err)
+	return c.client.HasClient(name)
+}
+
+func (c instrumentedUAAClient) CreateClient(name, secret string, authorities []string) (err error) {
+	defer c.observe("CreateClient", time.Now(), &err)
+	return c.client.CreateClient(name, secret, authorities)
+}
+
+func (c instrumentedUAAClient) UpdateClient(name string, authorities []string) (err error) {
+	defer c.observe("UpdateClient", time.Now(), &err)
+	return c.client.UpdateClient(name, authorities)
+}
+
+func (c instrumentedUAAClient) DeleteClient(name string) (err error) {
+	defer c.observe("DeleteClient", time.Now(), &err)
+	return c.client.DeleteClient(name)
+}
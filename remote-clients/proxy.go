@@ -0,0 +1,268 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+)
+
+// Proxy describes how to reach a Director and its UAA when they are not
+// directly reachable, in the same form as the bosh CLI's BOSH_ALL_PROXY:
+// either socks5://host:port, or ssh+socks5://user@host:port together with
+// the private key used to SSH to that host and the host's keys, in
+// known_hosts format.
+type Proxy struct {
+	URL        string
+	PrivateKey string
+	KnownHosts string
+}
+
+type dialFunc func(network, address string) (net.Conn, error)
+
+var directDialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// proxyDialer is the dialer built for a Director's proxy, and, for an SSH
+// proxy, the tunnel it holds open.
+type proxyDialer struct {
+	proxy  Proxy
+	dial   dialFunc
+	tunnel *sshDialer
+}
+
+var (
+	proxyDialersMu sync.Mutex
+	proxyDialers   = make(map[string]proxyDialer)
+)
+
+// dialer returns a dial function which connects through the proxy, or
+// directly if no proxy is configured. Dial functions are shared between all
+// clients of the same Director, so that each jumpbox is only SSHed to once,
+// and are replaced, closing any tunnel, once the Director's proxy changes.
+func (p Proxy) dialer(director string) (dialFunc, error) {
+	proxyDialersMu.Lock()
+	defer proxyDialersMu.Unlock()
+
+	existing, ok := proxyDialers[director]
+	if ok && existing.proxy == p {
+		return existing.dial, nil
+	}
+
+	if ok {
+		delete(proxyDialers, director)
+		if existing.tunnel != nil {
+			existing.tunnel.Close()
+		}
+	}
+
+	if p.URL == "" {
+		return directDialer.Dial, nil
+	}
+
+	d, err := p.newDialer()
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDialers[director] = d
+	return d.dial, nil
+}
+
+func (p Proxy) newDialer() (proxyDialer, error) {
+	if strings.HasPrefix(p.URL, "ssh+") {
+		tunnel, err := p.newSSHDialer()
+		if err != nil {
+			return proxyDialer{}, err
+		}
+
+		return proxyDialer{proxy: p, dial: tunnel.Dial, tunnel: tunnel}, nil
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return proxyDialer{}, fmt.Errorf("parsing proxy url: %s", err)
+	}
+
+	if proxyURL.Scheme != "socks5" {
+		return proxyDialer{}, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	d, err := proxy.FromURL(proxyURL, directDialer)
+	if err != nil {
+		return proxyDialer{}, fmt.Errorf("parsing proxy url: %s", err)
+	}
+
+	return proxyDialer{proxy: p, dial: d.Dial}, nil
+}
+
+func (p Proxy) newSSHDialer() (*sshDialer, error) {
+	proxyURL, err := url.Parse(strings.TrimPrefix(p.URL, "ssh+"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy url: %s", err)
+	}
+
+	if proxyURL.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme %q", "ssh+"+proxyURL.Scheme)
+	}
+
+	if p.PrivateKey == "" {
+		return nil, fmt.Errorf("ssh+socks5 proxy requires a private key")
+	}
+
+	// The jumpbox sees the Director's and UAA's credentials, so its host key
+	// is never accepted on first use.
+	if strings.TrimSpace(p.KnownHosts) == "" {
+		return nil, fmt.Errorf("ssh+socks5 proxy requires the jumpbox's known hosts")
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(p.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy private key: %s", err)
+	}
+
+	hostKeyCallback, err := parseKnownHosts(p.KnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("parsing proxy known hosts: %s", err)
+	}
+
+	host := proxyURL.Host
+	if proxyURL.Port() == "" {
+		host = net.JoinHostPort(proxyURL.Hostname(), "22")
+	}
+
+	username := ""
+	if proxyURL.User != nil {
+		username = proxyURL.User.Username()
+	}
+
+	return &sshDialer{
+		host: host,
+		config: &ssh.ClientConfig{
+			User:            username,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         directDialer.Timeout,
+		},
+	}, nil
+}
+
+// parseKnownHosts builds a host key callback from known_hosts content, which
+// the knownhosts package only reads from files.
+func parseKnownHosts(knownHosts string) (ssh.HostKeyCallback, error) {
+	f, err := ioutil.TempFile("", "boshv3-known-hosts-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(knownHosts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return knownhosts.New(f.Name())
+}
+
+// sshDialer tunnels connections over an SSH connection to a jumpbox, which
+// is established on first use and re-established if it fails.
+type sshDialer struct {
+	host   string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+	closed bool
+}
+
+func (d *sshDialer) Dial(network, address string) (net.Conn, error) {
+	client, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial(network, address)
+	if err != nil {
+		// The jumpbox rejects the channel for a target it can't reach, which
+		// leaves the tunnel usable; any other error means the tunnel failed.
+		if _, rejected := err.(*ssh.OpenChannelError); !rejected {
+			d.reset(client)
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (d *sshDialer) connect() (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, fmt.Errorf("SSH tunnel to %s has been closed, since the Director's proxy changed", d.host)
+	}
+
+	if d.client == nil {
+		client, err := ssh.Dial("tcp", d.host, d.config)
+		if err != nil {
+			return nil, fmt.Errorf("connecting to SSH proxy %s: %s", d.host, err)
+		}
+		d.client = client
+	}
+
+	return d.client, nil
+}
+
+// reset closes a failed tunnel, unless it has already been replaced.
+func (d *sshDialer) reset(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == client {
+		d.client = nil
+	}
+	client.Close()
+}
+
+func (d *sshDialer) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	if d.client == nil {
+		return nil
+	}
+
+	err := d.client.Close()
+	d.client = nil
+	return err
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GitRelease is a BOSH release directory in a git repository, at a branch,
+// tag or commit, cloned with either a username and password or an SSH
+// private key if the repository isn't public. Over SSH, the repository's host
+// key must be in KnownHosts.
+type GitRelease struct {
+	URL        string
+	Ref        string
+	Username   string
+	Password   string
+	PrivateKey string
+	KnownHosts string
+}
+
+// BuiltRelease is a release tarball created from a GitRelease, in a
+// workspace which is removed by Cleanup.
+type BuiltRelease struct {
+	Path      string
+	Version   string
+	workspace string
+}
+
+func (b BuiltRelease) Cleanup() {
+	os.RemoveAll(b.workspace)
+}
+
+var (
+	commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+	// gitSCPPattern matches the scp-like form of an SSH URL, user@host:path,
+	// whose user and host can't be mistaken for options, and which git won't
+	// parse as another protocol's URL, e.g. file:///path or ext::command.
+	gitSCPPattern = regexp.MustCompile(`^(\w[\w.-]*@)?\w[\w.-]*:([^:/]|/[^/])`)
+)
+
+// gitProtocols are the only protocols git may use, including for submodules,
+// so that a repository can't read the controller's own filesystem.
+const gitProtocols = "https:ssh"
+
+// validateGitURL rejects repository URLs which aren't HTTPS or SSH, or which
+// git or ssh could parse as an option.
+func validateGitURL(repoURL string) error {
+	if !strings.HasPrefix(repoURL, "https://") && !strings.HasPrefix(repoURL, "ssh://") {
+		if gitSCPPattern.MatchString(repoURL) {
+			return nil
+		}
+		return InvalidGitURLError{URL: repoURL, Reason: "must be an https:// or ssh:// URL, or user@host:path"}
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return InvalidGitURLError{URL: repoURL, Reason: err.Error()}
+	}
+
+	if u.Hostname() == "" || strings.HasPrefix(u.Hostname(), "-") || strings.HasPrefix(u.User.Username(), "-") {
+		return InvalidGitURLError{URL: repoURL, Reason: "must have a host, and neither it nor the user may begin with -"}
+	}
+
+	return nil
+}
+
+// IsCommitSHA is true if ref names a single commit, rather than a branch or
+// tag which may move.
+func IsCommitSHA(ref string) bool {
+	return commitSHAPattern.MatchString(ref)
+}
+
+// ResolveGitRef returns the commit SHA the release's ref currently points
+// to, without cloning the repository.
+func ResolveGitRef(repo GitRelease) (string, error) {
+	if err := validateGitURL(repo.URL); err != nil {
+		return "", err
+	}
+
+	if IsCommitSHA(repo.Ref) {
+		return repo.Ref, nil
+	}
+
+	workspace, err := ioutil.TempDir("", "boshv3-git-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workspace)
+
+	patterns := []string{repo.Ref}
+	if !strings.HasPrefix(repo.Ref, "refs/") {
+		patterns = []string{"refs/heads/" + repo.Ref, "refs/tags/" + repo.Ref}
+	}
+
+	out, err := runGit(repo, workspace, "", append([]string{"ls-remote", "--", repo.URL}, patterns...)...)
+	if err != nil {
+		return "", err
+	}
+
+	// An annotated tag is listed twice, and its peeled "^{}" entry is the
+	// commit rather than the tag object.
+	var sha string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if sha == "" || strings.HasSuffix(fields[1], "^{}") {
+			sha = fields[0]
+		}
+	}
+
+	if sha == "" {
+		return "", fmt.Errorf("ref %q not found in git repository %s", repo.Ref, repo.URL)
+	}
+
+	return sha, nil
+}
+
+// CreateRelease clones the repository at commitSHA and runs the equivalent
+// of `bosh create-release --tarball`, creating a dev release versioned by
+// the number of commits leading to commitSHA, so that newer commits on a
+// branch create newer versions, and by the commit's short SHA, so that
+// different commits with as many ancestors, e.g. on another branch or after
+// a force-push, don't create the same version.
+func CreateRelease(repo GitRelease, commitSHA, name, versionPrefix string) (BuiltRelease, error) {
+	if err := validateGitURL(repo.URL); err != nil {
+		return BuiltRelease{}, err
+	}
+
+	if !IsCommitSHA(commitSHA) {
+		return BuiltRelease{}, fmt.Errorf("%q is not a commit SHA", commitSHA)
+	}
+
+	workspace, err := ioutil.TempDir("", "boshv3-git-")
+	if err != nil {
+		return BuiltRelease{}, err
+	}
+
+	built := BuiltRelease{
+		Path:      filepath.Join(workspace, "release.tgz"),
+		workspace: workspace,
+	}
+
+	releaseDir := filepath.Join(workspace, "release")
+
+	for _, args := range [][]string{
+		{"clone", "--no-checkout", "--", repo.URL, releaseDir},
+		{"-C", releaseDir, "checkout", "--quiet", commitSHA},
+		{"-C", releaseDir, "submodule", "update", "--init", "--recursive"},
+	} {
+		if _, err := runGit(repo, workspace, "", args...); err != nil {
+			built.Cleanup()
+			return BuiltRelease{}, err
+		}
+	}
+
+	count, err := runGit(repo, workspace, releaseDir, "rev-list", "--count", commitSHA)
+	if err != nil {
+		built.Cleanup()
+		return BuiltRelease{}, err
+	}
+
+	if versionPrefix == "" {
+		versionPrefix = "0"
+	}
+	built.Version = fmt.Sprintf("%s+dev.%s.%s", versionPrefix, strings.TrimSpace(count), commitSHA[:7])
+
+	if _, err := run(
+		exec.Command(
+			"bosh", "create-release",
+			"--dir", releaseDir,
+			"--name", name,
+			"--version", built.Version,
+			"--tarball", built.Path,
+			"--force",
+		),
+	); err != nil {
+		built.Cleanup()
+		return BuiltRelease{}, err
+	}
+
+	return built, nil
+}
+
+// gitAskPass answers git's username and password prompts from the
+// environment, so that credentials never appear in arguments or URLs.
+const gitAskPass = `#!/bin/sh
+case "$1" in
+  Username*) echo "$BOSHV3_GIT_USERNAME" ;;
+  *) echo "$BOSHV3_GIT_PASSWORD" ;;
+esac
+`
+
+func runGit(repo GitRelease, workspace, dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+gitProtocols)
+
+	if repo.Username != "" || repo.Password != "" {
+		askPass := filepath.Join(workspace, ".askpass")
+		if err := ioutil.WriteFile(askPass, []byte(gitAskPass), 0700); err != nil {
+			return "", err
+		}
+
+		cmd.Env = append(
+			cmd.Env,
+			"GIT_ASKPASS="+askPass,
+			"BOSHV3_GIT_USERNAME="+repo.Username,
+			"BOSHV3_GIT_PASSWORD="+repo.Password,
+		)
+	}
+
+	// Host keys are never accepted on first use, since the repository
+	// supplies code which runs on the Director's VMs.
+	knownHosts := filepath.Join(workspace, ".known_hosts")
+	if err := ioutil.WriteFile(knownHosts, []byte(repo.KnownHosts), 0600); err != nil {
+		return "", err
+	}
+	sshCommand := "ssh -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + knownHosts
+
+	if repo.PrivateKey != "" {
+		key := filepath.Join(workspace, ".ssh-key")
+		if err := ioutil.WriteFile(key, []byte(repo.PrivateKey), 0600); err != nil {
+			return "", err
+		}
+
+		sshCommand += " -i " + key + " -o IdentitiesOnly=yes"
+	}
+
+	cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND="+sshCommand)
+
+	return run(cmd)
+}
+
+func run(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"%s %s: %s: %s",
+			filepath.Base(cmd.Path),
+			strings.Join(cmd.Args[1:], " "),
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return stdout.String(), nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package remoteclients
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -26,8 +27,10 @@ import (
 )
 
 type UAAClient interface {
+	Authenticate() error
 	HasClient(string) (bool, error)
 	CreateClient(string, string, []string) error
+	UpdateClient(string, []string) error
 	DeleteClient(string) error
 }
 
@@ -36,10 +39,12 @@ type uaaClientImpl struct {
 }
 
 func NewUAAClient(
+	director string,
 	url string,
 	clientName string,
 	clientSecret string,
 	caCert string,
+	proxy Proxy,
 ) (UAAClient, error) {
 	rootCAs, _ := x509.SystemCertPool()
 	if rootCAs == nil {
@@ -52,6 +57,11 @@ func NewUAAClient(
 		return nil, err
 	}
 
+	dial, err := proxy.dialer(director)
+	if err != nil {
+		return nil, err
+	}
+
 	api := (&uaa.API{
 		UserAgent: "go-uaa",
 		TargetURL: targetURL,
@@ -59,6 +69,7 @@ func NewUAAClient(
 		&http.Client{
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{RootCAs: rootCAs},
+				Dial:            dial,
 			},
 		},
 	).WithClientCredentials(
@@ -70,10 +81,18 @@ func NewUAAClient(
 	if err = api.Validate(); err != nil {
 		return nil, err
 	} else {
-		return &uaaClientImpl{api: api}, nil
+		return instrumentedUAAClient{
+			director: director,
+			client:   &uaaClientImpl{api: api},
+		}, nil
 	}
 }
 
+func (c *uaaClientImpl) Authenticate() error {
+	_, err := c.api.Token(context.Background())
+	return err
+}
+
 func (c *uaaClientImpl) HasClient(name string) (bool, error) {
 	if clients, _, err := c.api.ListClients(
 		fmt.Sprintf("client_id eq \"%s\"", name),
@@ -101,6 +120,17 @@ func (c *uaaClientImpl) CreateClient(name, secret string, authorities []string)
 	return err
 }
 
+func (c *uaaClientImpl) UpdateClient(name string, authorities []string) error {
+	client, err := c.api.GetClient(name)
+	if err != nil {
+		return err
+	}
+
+	client.Authorities = authorities
+	_, err = c.api.UpdateClient(*client)
+	return err
+}
+
 func (c *uaaClientImpl) DeleteClient(name string) error {
 	_, err := c.api.DeleteClient(name)
 	return err
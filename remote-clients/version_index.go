@@ -0,0 +1,236 @@
+/*
+Copyright 2019 Amit Kumar Gupta.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteclients
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	semiver "github.com/cppforlife/go-semi-semantic/version"
+)
+
+// IndexedVersion is a version of a release or stemcell listed by a
+// bosh.io-compatible index, with the URL and SHA1 of its tarball.
+type IndexedVersion struct {
+	Version string
+	URL     string
+	SHA1    string
+}
+
+var defaultIndexURL = "https://bosh.io"
+
+// SetDefaultIndexURL sets the bosh.io-compatible index used by Releases and
+// BaseImages which don't name one.
+func SetDefaultIndexURL(url string) {
+	defaultIndexURL = url
+}
+
+// IsVersionConstraint is true if version is "latest" or a constraint such as
+// "~> 315.0" or ">= 1.2, < 2", rather than a pinned version.
+func IsVersionConstraint(version string) bool {
+	return version == "latest" || strings.IndexAny(strings.TrimSpace(version), "<>=!~") == 0
+}
+
+// ReleaseVersions lists the versions of a release, identified by its source
+// repository, e.g. github.com/cppforlife/zookeeper-release.
+func ReleaseVersions(indexURL, repository string) ([]IndexedVersion, error) {
+	var entries []struct {
+		Version string `json:"version"`
+		URL     string `json:"url"`
+		SHA1    string `json:"sha1"`
+	}
+
+	if err := getIndex(indexURL, "releases/"+repository, &entries); err != nil {
+		return nil, err
+	}
+
+	versions := make([]IndexedVersion, len(entries))
+	for i, e := range entries {
+		versions[i] = IndexedVersion{Version: e.Version, URL: e.URL, SHA1: e.SHA1}
+	}
+
+	return versions, nil
+}
+
+// BaseImageVersions lists the versions of a stemcell, preferring light
+// stemcells where the index has them.
+func BaseImageVersions(indexURL, name string) ([]IndexedVersion, error) {
+	type tarball struct {
+		URL  string `json:"url"`
+		SHA1 string `json:"sha1"`
+	}
+
+	var entries []struct {
+		Version string   `json:"version"`
+		Light   *tarball `json:"light"`
+		Regular *tarball `json:"regular"`
+	}
+
+	if err := getIndex(indexURL, "stemcells/"+name, &entries); err != nil {
+		return nil, err
+	}
+
+	var versions []IndexedVersion
+	for _, e := range entries {
+		t := e.Light
+		if t == nil {
+			t = e.Regular
+		}
+		if t == nil {
+			continue
+		}
+
+		versions = append(versions, IndexedVersion{Version: e.Version, URL: t.URL, SHA1: t.SHA1})
+	}
+
+	return versions, nil
+}
+
+func getIndex(indexURL, path string, entries interface{}) error {
+	if indexURL == "" {
+		indexURL = defaultIndexURL
+	}
+
+	url := strings.TrimSuffix(indexURL, "/") + "/api/v1/" + path
+
+	resp, err := apiClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return HTTPStatusError{URL: url, Code: resp.StatusCode}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(entries)
+}
+
+// InvalidVersionConstraintError is returned for a version constraint which
+// can't be parsed.
+type InvalidVersionConstraintError struct {
+	Constraint string
+	Reason     string
+}
+
+func (e InvalidVersionConstraintError) Error() string {
+	return fmt.Sprintf("invalid version constraint %q: %s", e.Constraint, e.Reason)
+}
+
+// LatestMatchingVersion returns the newest of versions which satisfies
+// constraint, and false if none do.
+func LatestMatchingVersion(versions []IndexedVersion, constraint string) (IndexedVersion, bool, error) {
+	matches, err := parseConstraint(constraint)
+	if err != nil {
+		return IndexedVersion{}, false, err
+	}
+
+	var latest IndexedVersion
+	var latestVersion semiver.Version
+	found := false
+
+	for _, v := range versions {
+		parsed, err := semiver.NewVersionFromString(v.Version)
+		if err != nil || !matches(parsed) {
+			continue
+		}
+
+		if !found || parsed.IsGt(latestVersion) {
+			latest, latestVersion, found = v, parsed, true
+		}
+	}
+
+	return latest, found, nil
+}
+
+// parseConstraint parses "latest", or comma-separated clauses each made of
+// one of the operators =, !=, >, >=, <, <= and ~> followed by a version,
+// where "~> 315.0" allows any 315.x and "~> 1.2.3" any 1.2.x from 1.2.3.
+func parseConstraint(constraint string) (func(semiver.Version) bool, error) {
+	if constraint == "latest" {
+		return func(semiver.Version) bool { return true }, nil
+	}
+
+	var clauses []func(semiver.Version) bool
+
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+
+		version := strings.TrimLeft(clause, "<>=!~ ")
+		op := strings.TrimSpace(clause[:len(clause)-len(version)])
+
+		bound, err := semiver.NewVersionFromString(version)
+		if err != nil {
+			return nil, InvalidVersionConstraintError{Constraint: constraint, Reason: err.Error()}
+		}
+
+		var matches func(semiver.Version) bool
+		switch op {
+		case "", "=":
+			matches = func(v semiver.Version) bool { return v.IsEq(bound) }
+		case "!=":
+			matches = func(v semiver.Version) bool { return !v.IsEq(bound) }
+		case ">":
+			matches = func(v semiver.Version) bool { return v.IsGt(bound) }
+		case ">=":
+			matches = func(v semiver.Version) bool { return !v.IsLt(bound) }
+		case "<":
+			matches = func(v semiver.Version) bool { return v.IsLt(bound) }
+		case "<=":
+			matches = func(v semiver.Version) bool { return !v.IsGt(bound) }
+		case "~>":
+			upper, err := pessimisticUpperBound(version)
+			if err != nil {
+				return nil, InvalidVersionConstraintError{Constraint: constraint, Reason: err.Error()}
+			}
+			matches = func(v semiver.Version) bool { return !v.IsLt(bound) && v.IsLt(upper) }
+		default:
+			return nil, InvalidVersionConstraintError{Constraint: constraint, Reason: "unknown operator " + op}
+		}
+
+		clauses = append(clauses, matches)
+	}
+
+	return func(v semiver.Version) bool {
+		for _, matches := range clauses {
+			if !matches(v) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// pessimisticUpperBound is the exclusive upper bound of "~> version": the
+// version with its last component dropped and the one before incremented.
+func pessimisticUpperBound(version string) (semiver.Version, error) {
+	components := strings.Split(strings.SplitN(version, "-", 2)[0], ".")
+	if len(components) > 1 {
+		components = components[:len(components)-1]
+	}
+
+	last, err := strconv.Atoi(components[len(components)-1])
+	if err != nil {
+		return semiver.Version{}, err
+	}
+	components[len(components)-1] = strconv.Itoa(last + 1)
+
+	return semiver.NewVersionFromString(strings.Join(components, "."))
+}